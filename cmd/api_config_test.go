@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func TestApiV1ConvertConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name             string
+		defaultFormat    string
+		format           string
+		expectedMimeType string
+	}{
+		{
+			name:             "Configured Default Format Test",
+			defaultFormat:    "png",
+			expectedMimeType: "image/png",
+		},
+		{
+			name:             "Requested Format Test",
+			defaultFormat:    "png",
+			format:           "webp",
+			expectedMimeType: "image/webp",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Defaults.Format = tt.defaultFormat
+
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServerWithConfig(cfg, convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.format != "" {
+				if err := writer.WriteField("format", tt.format); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, recorder.Code)
+			}
+
+			var resp apiV1.ConvertResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(resp.Data) != 1 || !strings.HasPrefix(resp.Data[0], "data:"+tt.expectedMimeType+";base64,") {
+				t.Errorf("expected %s data URI, got %v", tt.expectedMimeType, resp.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func newConvertRequest(t *testing.T, path string) *http.Request {
+	t.Helper()
+
+	return newConvertRequestWithContent(t, path, "%PDF-1.5\n%%EOF\n")
+}
+
+// newConvertRequestWithContent uploads content as the document
+func newConvertRequestWithContent(t *testing.T, path, content string) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("data", "test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(part, strings.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestApiConvertServerBusy(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "V1 Convert Test",
+			path: "/v1/convert",
+		},
+		{
+			name: "V2 Convert Test",
+			path: "/v2/convert",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := &MockBlockingImageConvertService{
+				started: make(chan struct{}, 1),
+				release: make(chan struct{}),
+			}
+			limited := service.NewLimitedImageConvertService(converter, service.LimitedImageConvertOptions{
+				Concurrency:  1,
+				QueueSize:    0,
+				QueueTimeout: time.Second,
+				RetryAfter:   1500 * time.Millisecond,
+			})
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				limited,
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			running := make(chan int, 1)
+			go func() {
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, newConvertRequest(t, tt.path))
+				running <- recorder.Code
+			}()
+			<-converter.started
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, newConvertRequest(t, tt.path))
+
+			close(converter.release)
+			if code := <-running; code != http.StatusOK {
+				t.Errorf("expected the running conversion to succeed, got %d", code)
+			}
+
+			if recorder.Code != http.StatusServiceUnavailable {
+				t.Fatalf("expected status code %d, got %d", http.StatusServiceUnavailable, recorder.Code)
+			}
+
+			if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != "2" {
+				t.Errorf("expected Retry-After 2, got %q", retryAfter)
+			}
+
+			var resp apiV1.Error
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if resp.Code != apiV1.ErrCodeServerBusy {
+				t.Errorf("expected error code %d, got %d", apiV1.ErrCodeServerBusy, resp.Code)
+			}
+		})
+	}
+}
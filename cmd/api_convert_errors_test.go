@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// MockErrorImageConvertService fails every conversion with err
+type MockErrorImageConvertService struct {
+	err error
+}
+
+func (m *MockErrorImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	return nil, m.err
+}
+
+func TestApiConvertErrors(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedCode    apiV1.ErrorCode
+		expectedMessage string
+	}{
+		{
+			name:            "Resource Limit Exceeded Test",
+			err:             fmt.Errorf("%w: pixel cache limit", usecase.ErrResourceLimitExceeded),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apiV1.ErrCodeResourceLimitExceeded,
+			expectedMessage: "Conversion exceeded the resource limits: pixel cache limit",
+		},
+		{
+			name:            "Conversion Timeout Test",
+			err:             fmt.Errorf("%w after 2m0s", usecase.ErrConversionTimeout),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apiV1.ErrCodeConversionTimeout,
+			expectedMessage: "Conversion timed out after 2m0s",
+		},
+		{
+			name:            "Invalid Password Test",
+			err:             usecase.ErrInvalidPassword,
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    apiV1.ErrCodeInvalidPassword,
+			expectedMessage: "Password is incorrect for encrypted PDF",
+		},
+		{
+			name:            "Unsupported Document Test",
+			err:             usecase.ErrUnsupportedDocument,
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedCode:    apiV1.ErrCodeUnsupportedDocument,
+			expectedMessage: "Document is not a PDF",
+		},
+		{
+			name:            "Corrupt Document Test",
+			err:             usecase.ErrCorruptDocument,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apiV1.ErrCodeCorruptDocument,
+			expectedMessage: "Document is corrupt and cannot be read",
+		},
+		{
+			name:            "Too Many Pages Test",
+			err:             usecase.ErrTooManyPages,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apiV1.ErrCodeTooManyPages,
+			expectedMessage: "Document has too many pages",
+		},
+		{
+			name:            "Internal Error Test",
+			err:             errors.New("failed to convert PDF to images: exit status 1, stderr: /tmp/pdf64-123/upload.pdf"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCode:    apiV1.ErrCodeInternal,
+			expectedMessage: "Conversion failed",
+		},
+	}
+
+	for _, path := range []string{"/v1/convert", "/v2/convert"} {
+		for _, tt := range tests {
+			t.Run(path+" "+tt.name, func(t *testing.T) {
+				convertUsecase := usecase.NewConvertUsecase(
+					&MockFileBuilder{},
+					&MockErrorImageConvertService{err: tt.err},
+					NewMockPdfDecryptService(false),
+					&MockPdfPageCountService{},
+					&MockPdfTextService{},
+					metrics.New(),
+					usecase.ConvertLimits{},
+				)
+				server := newTestServer(convertUsecase)
+
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, newConvertRequest(t, path))
+
+				if recorder.Code != tt.expectedStatus {
+					t.Fatalf("expected status code %d, got %d", tt.expectedStatus, recorder.Code)
+				}
+
+				var resp apiV1.Error
+				if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+
+				if resp.Code != tt.expectedCode {
+					t.Errorf("expected error code %d, got %d", tt.expectedCode, resp.Code)
+				}
+
+				if resp.Message != tt.expectedMessage {
+					t.Errorf("expected message %q, got %q", tt.expectedMessage, resp.Message)
+				}
+			})
+		}
+	}
+}
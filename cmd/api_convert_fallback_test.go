@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	apiV2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+func TestApiConvertRendererFallback(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.5\n%%EOF\n"))
+
+	tests := []struct {
+		name              string
+		path              string
+		renderer          string
+		defaultErr        error
+		fallbackErr       error
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		expectedRenderer  string
+	}{
+		{
+			name:             "Default Renderer Succeeds Test",
+			path:             "/v1/convert",
+			expectedStatus:   http.StatusOK,
+			expectedRenderer: "ghostscript",
+		},
+		{
+			name:             "Fallback On Corrupt Document Test",
+			path:             "/v1/convert",
+			defaultErr:       usecase.ErrCorruptDocument,
+			expectedStatus:   http.StatusOK,
+			expectedRenderer: "mutool",
+		},
+		{
+			name:             "Fallback On V2 Test",
+			path:             "/v2/convert",
+			defaultErr:       usecase.ErrCorruptDocument,
+			expectedStatus:   http.StatusOK,
+			expectedRenderer: "mutool",
+		},
+		{
+			name:              "Every Renderer Fails Test",
+			path:              "/v1/convert",
+			defaultErr:        usecase.ErrCorruptDocument,
+			fallbackErr:       usecase.ErrCorruptDocument,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedErrorCode: apiV1.ErrCodeCorruptDocument,
+		},
+		{
+			name:              "No Fallback On Timeout Test",
+			path:              "/v1/convert",
+			defaultErr:        usecase.ErrConversionTimeout,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedErrorCode: apiV1.ErrCodeConversionTimeout,
+		},
+		{
+			name:              "No Fallback For Selected Renderer Test",
+			path:              "/v1/convert",
+			renderer:          "ghostscript",
+			defaultErr:        usecase.ErrCorruptDocument,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedErrorCode: apiV1.ErrCodeCorruptDocument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderers := map[usecase.Renderer]usecase.ImageConvertService{
+				usecase.RendererGhostscript: &MockImageConvertService{},
+				usecase.RendererMutool:      &MockImageConvertService{},
+			}
+			if tt.defaultErr != nil {
+				renderers[usecase.RendererGhostscript] = &MockErrorImageConvertService{err: tt.defaultErr}
+			}
+			if tt.fallbackErr != nil {
+				renderers[usecase.RendererMutool] = &MockErrorImageConvertService{err: tt.fallbackErr}
+			}
+
+			converter := service.NewFallbackImageConvertService(
+				service.NewRendererImageConvertService(usecase.RendererGhostscript, renderers),
+				[]usecase.Renderer{usecase.RendererGhostscript, usecase.RendererMutool},
+			)
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				converter,
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body, err := json.Marshal(map[string]string{"data": encoded, "renderer": tt.renderer})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedErrorCode != 0 {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			if tt.path == "/v2/convert" {
+				var resp apiV2.ConvertResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to decode response: %v", err)
+				}
+
+				for _, image := range resp.Data {
+					if image.Renderer != tt.expectedRenderer {
+						t.Errorf("expected image renderer %q, got %q", tt.expectedRenderer, image.Renderer)
+					}
+				}
+				return
+			}
+
+			var resp apiV1.ConvertResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if resp.Renderer != tt.expectedRenderer {
+				t.Errorf("expected renderer %q, got %q", tt.expectedRenderer, resp.Renderer)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// MockRecordingFileBuilder records the content of the built file
+type MockRecordingFileBuilder struct {
+	MockFileBuilder
+	content []byte
+}
+
+// BuildFromPath reads the uploaded content before building the file
+func (m *MockRecordingFileBuilder) BuildFromPath(ctx context.Context, path string) (*entity.File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m.content = content
+
+	return m.MockFileBuilder.BuildFromPath(ctx, path)
+}
+
+func TestApiV1ConvertJSON(t *testing.T) {
+	document := "%PDF-1.5\n%%EOF\n"
+	encoded := base64.StdEncoding.EncodeToString([]byte(document))
+
+	tests := []struct {
+		name              string
+		body              any
+		isEncrypted       bool
+		pageCount         int
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		validateResp      func(t *testing.T, resp *apiV1.ConvertResponse)
+	}{
+		{
+			name:           "Base64 Data Test",
+			body:           map[string]any{"data": encoded, "density": "300", "quality": 80},
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				if len(resp.Data) != 1 {
+					t.Errorf("expected 1 image, got %d", len(resp.Data))
+				}
+			},
+		},
+		{
+			name:           "Data URI Test",
+			body:           map[string]any{"data": "data:application/pdf;base64," + encoded},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Merge Pages Test",
+			body:           map[string]any{"data": encoded, "merge": true},
+			pageCount:      3,
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				if len(resp.Data) != 1 {
+					t.Errorf("expected 1 merged image, got %d", len(resp.Data))
+				}
+			},
+		},
+		{
+			name:           "Password Protected PDF Test",
+			body:           map[string]any{"data": encoded, "password": "secret123"},
+			isEncrypted:    true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:              "Encrypted PDF Without Password Test",
+			body:              map[string]any{"data": encoded},
+			isEncrypted:       true,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodePasswordRequired,
+		},
+		{
+			name:              "Invalid Page Range Test",
+			body:              map[string]any{"data": encoded, "pages": "5"},
+			pageCount:         3,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidPageRange,
+		},
+		{
+			name:              "Out Of Range Quality Test",
+			body:              map[string]any{"data": encoded, "quality": 150},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidParameter,
+		},
+		{
+			name:              "Missing Data Test",
+			body:              map[string]any{"density": "300"},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+		{
+			name:              "Invalid Base64 Test",
+			body:              map[string]any{"data": "not base64!"},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+		{
+			name:              "Data URI Without Base64 Test",
+			body:              map[string]any{"data": "data:application/pdf," + encoded},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+		{
+			name:              "Malformed JSON Test",
+			body:              "{",
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileBuilder := &MockRecordingFileBuilder{MockFileBuilder: MockFileBuilder{isEncrypted: tt.isEncrypted}}
+			convertUsecase := usecase.NewConvertUsecase(
+				fileBuilder,
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(true),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body, ok := tt.body.(string)
+			if !ok {
+				encodedBody, err := json.Marshal(tt.body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = string(encodedBody)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			if string(fileBuilder.content) != document {
+				t.Errorf("expected decoded document %q, got %q", document, fileBuilder.content)
+			}
+
+			var resp apiV1.ConvertResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if tt.validateResp != nil {
+				tt.validateResp(t, &resp)
+			}
+		})
+	}
+}
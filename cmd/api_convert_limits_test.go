@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func TestApiConvertLimits(t *testing.T) {
+	largeDocument := "%PDF-1.5\n" + strings.Repeat("%", 2048) + "\n%%EOF\n"
+
+	tests := []struct {
+		name            string
+		path            string
+		newRequest      func(t *testing.T, path string) *http.Request
+		limits          usecase.ConvertLimits
+		pageCount       int
+		expectedStatus  int
+		expectedCode    apiV1.ErrorCode
+		expectedMessage string
+	}{
+		{
+			name: "V1 Multipart Upload Too Large Test",
+			path: "/v1/convert",
+			newRequest: func(t *testing.T, path string) *http.Request {
+				return newConvertRequestWithContent(t, path, largeDocument)
+			},
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedCode:    apiV1.ErrCodeMaxFileSize,
+			expectedMessage: "Document is too large: exceeds the upload limit of 1024 bytes",
+		},
+		{
+			name: "V1 JSON Upload Too Large Test",
+			path: "/v1/convert",
+			newRequest: func(t *testing.T, path string) *http.Request {
+				body, err := json.Marshal(map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(largeDocument))})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req := httptest.NewRequest("POST", path, strings.NewReader(string(body)))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedCode:    apiV1.ErrCodeMaxFileSize,
+			expectedMessage: "Document is too large: exceeds the upload limit of 1024 bytes",
+		},
+		{
+			name: "V1 JSON Upload Within Limit Test",
+			path: "/v1/convert",
+			newRequest: func(t *testing.T, path string) *http.Request {
+				document := "%PDF-1.5\n" + strings.Repeat("%", 1000) + "\n%%EOF\n"
+				body, err := json.Marshal(map[string]string{"data": base64.StdEncoding.EncodeToString([]byte(document))})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req := httptest.NewRequest("POST", path, strings.NewReader(string(body)))
+				req.Header.Set("Content-Type", "application/json")
+				return req
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "V2 Upload Too Large Test",
+			path: "/v2/convert",
+			newRequest: func(t *testing.T, path string) *http.Request {
+				return newConvertRequestWithContent(t, path, largeDocument)
+			},
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedCode:    apiV1.ErrCodeMaxFileSize,
+			expectedMessage: "Document is too large: exceeds the upload limit of 1024 bytes",
+		},
+		{
+			name:            "V1 Too Many Pages Test",
+			path:            "/v1/convert",
+			newRequest:      newConvertRequest,
+			limits:          usecase.ConvertLimits{MaxPages: 2},
+			pageCount:       3,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedCode:    apiV1.ErrCodeTooManyPages,
+			expectedMessage: "Document has too many pages: 3 pages exceed the limit of 2",
+		},
+		{
+			name:            "V2 Output Too Large Test",
+			path:            "/v2/convert",
+			newRequest:      newConvertRequest,
+			limits:          usecase.ConvertLimits{MaxOutputBytes: 1000},
+			pageCount:       3,
+			expectedStatus:  http.StatusRequestEntityTooLarge,
+			expectedCode:    apiV1.ErrCodeMaxFileSize,
+			expectedMessage: "Output is too large: exceeds 1000 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Limits.MaxUploadSize = 1024
+
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				tt.limits,
+			)
+			server := newTestServerWithConfig(cfg, convertUsecase)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, tt.newRequest(t, tt.path))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedStatus == http.StatusOK {
+				return
+			}
+
+			var resp apiV1.Error
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if resp.Code != tt.expectedCode {
+				t.Errorf("expected error code %d, got %d", tt.expectedCode, resp.Code)
+			}
+
+			if resp.Message != tt.expectedMessage {
+				t.Errorf("expected message %q, got %q", tt.expectedMessage, resp.Message)
+			}
+		})
+	}
+}
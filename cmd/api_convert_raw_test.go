@@ -0,0 +1,260 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func TestApiV1ConvertRaw(t *testing.T) {
+	tests := []struct {
+		name                string
+		accept              string
+		fields              map[string]string
+		pageCount           int
+		expectedStatus      int
+		expectedContentType string
+		validateResp        func(t *testing.T, resp *http.Response, body []byte)
+	}{
+		{
+			name:                "Single Raw Image Test",
+			accept:              "image/png",
+			fields:              map[string]string{"pages": "2"},
+			pageCount:           3,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/png",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				if len(body) == 0 {
+					t.Error("expected non-empty image body")
+				}
+
+				if resp.Header.Get("X-Page") != "2" {
+					t.Errorf("expected X-Page to be 2, got %s", resp.Header.Get("X-Page"))
+				}
+
+				if len(resp.Header.Get("X-File-Id")) < 32 {
+					t.Errorf("expected UUID format for X-File-Id, got: %s", resp.Header.Get("X-File-Id"))
+				}
+			},
+		},
+		{
+			name:                "Merged Raw Image Test",
+			accept:              "image/*",
+			fields:              map[string]string{"merge": "true"},
+			pageCount:           3,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/jpeg",
+		},
+		{
+			name:                "Multiple Raw Images Test",
+			accept:              "image/*",
+			pageCount:           3,
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				assertErrorCode(t, body, apiV1.ErrCodeNotAcceptable)
+			},
+		},
+		{
+			name:                "ZIP Archive Test",
+			accept:              "application/zip",
+			pageCount:           2,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/zip",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+				if err != nil {
+					t.Fatalf("failed to read ZIP archive: %v", err)
+				}
+
+				names := make([]string, 0, len(archive.File))
+				for _, file := range archive.File {
+					names = append(names, file.Name)
+				}
+
+				expectedNames := "manifest.json,page-1.jpg,page-2.jpg"
+				if strings.Join(names, ",") != expectedNames {
+					t.Errorf("expected files %s, got %s", expectedNames, strings.Join(names, ","))
+				}
+
+				manifestFile, err := archive.Open("manifest.json")
+				if err != nil {
+					t.Fatalf("failed to open manifest: %v", err)
+				}
+				defer manifestFile.Close()
+
+				var manifest apiV1.Manifest
+				if err := json.NewDecoder(manifestFile).Decode(&manifest); err != nil {
+					t.Fatalf("failed to decode manifest: %v", err)
+				}
+
+				if len(manifest.Images) != 2 || manifest.Images[1].Page != 2 {
+					t.Errorf("expected manifest for pages 1 and 2, got %+v", manifest.Images)
+				}
+			},
+		},
+		{
+			name:                "Multipart Mixed Test",
+			accept:              "multipart/mixed",
+			pageCount:           2,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "multipart/mixed",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				_, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+				if err != nil {
+					t.Fatalf("failed to parse Content-Type: %v", err)
+				}
+
+				reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+				var contentTypes []string
+				for {
+					part, err := reader.NextPart()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("failed to read part: %v", err)
+					}
+					contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+				}
+
+				expectedTypes := "application/json,image/jpeg,image/jpeg"
+				if strings.Join(contentTypes, ",") != expectedTypes {
+					t.Errorf("expected parts %s, got %s", expectedTypes, strings.Join(contentTypes, ","))
+				}
+			},
+		},
+		{
+			name:                "Browser Accept Falls Back To JSON Test",
+			accept:              "text/html,application/xhtml+xml,*/*;q=0.8",
+			pageCount:           1,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "Unsupported Image Type Test",
+			accept:              "image/svg+xml",
+			pageCount:           1,
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				assertErrorCode(t, body, apiV1.ErrCodeNotAcceptable)
+			},
+		},
+		{
+			name:                "Supported Image Type Preferred Test",
+			accept:              "image/svg+xml, image/webp;q=0.5",
+			pageCount:           1,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/webp",
+		},
+		{
+			name:                "Conflicting Format Test",
+			accept:              "image/png",
+			fields:              map[string]string{"format": "webp"},
+			pageCount:           1,
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				assertErrorCode(t, body, apiV1.ErrCodeNotAcceptable)
+			},
+		},
+		{
+			name:                "Matching Format Alias Test",
+			accept:              "image/jpeg",
+			fields:              map[string]string{"format": "JPG"},
+			pageCount:           1,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "image/jpeg",
+		},
+		{
+			name:                "Unsupported Accept Test",
+			accept:              "text/html",
+			pageCount:           1,
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+			validateResp: func(t *testing.T, resp *http.Response, body []byte) {
+				assertErrorCode(t, body, apiV1.ErrCodeNotAcceptable)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			for key, value := range tt.fields {
+				_ = writer.WriteField(key, value)
+			}
+
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Accept", tt.accept)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+			if contentType != tt.expectedContentType {
+				t.Errorf("expected Content-Type to be %s, got %s", tt.expectedContentType, contentType)
+			}
+
+			if tt.validateResp != nil {
+				tt.validateResp(t, resp, recorder.Body.Bytes())
+			}
+		})
+	}
+}
+
+func assertErrorCode(t *testing.T, body []byte, expected apiV1.ErrorCode) {
+	t.Helper()
+
+	var errorResp apiV1.Error
+	if err := json.Unmarshal(body, &errorResp); err != nil {
+		t.Fatalf("failed to unmarshal error response: %v", err)
+	}
+
+	if errorResp.Code != expected {
+		t.Errorf("expected error code %d, got %d", expected, errorResp.Code)
+	}
+}
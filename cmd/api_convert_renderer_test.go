@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// MockRendererImageConvertService records the renderer it is called as
+type MockRendererImageConvertService struct {
+	MockImageConvertService
+	renderer usecase.Renderer
+	used     *usecase.Renderer
+}
+
+func (m *MockRendererImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	*m.used = m.renderer
+	return m.MockImageConvertService.Convert(ctx, file, options)
+}
+
+func TestApiV1ConvertRenderer(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.5\n%%EOF\n"))
+
+	tests := []struct {
+		name              string
+		renderer          string
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		expectedRenderer  usecase.Renderer
+	}{
+		{
+			name:             "Default Renderer Test",
+			expectedStatus:   http.StatusOK,
+			expectedRenderer: usecase.RendererImageMagick,
+		},
+		{
+			name:             "Selected Renderer Test",
+			renderer:         "pdftoppm",
+			expectedStatus:   http.StatusOK,
+			expectedRenderer: usecase.RendererPdftoppm,
+		},
+		{
+			name:              "Unsupported Renderer Test",
+			renderer:          "pdfium",
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeUnsupportedRenderer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var used usecase.Renderer
+			converter := service.NewRendererImageConvertService(usecase.RendererImageMagick, map[usecase.Renderer]usecase.ImageConvertService{
+				usecase.RendererImageMagick: &MockRendererImageConvertService{renderer: usecase.RendererImageMagick, used: &used},
+				usecase.RendererPdftoppm:    &MockRendererImageConvertService{renderer: usecase.RendererPdftoppm, used: &used},
+			})
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				converter,
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body, err := json.Marshal(map[string]string{"data": encoded, "renderer": tt.renderer})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedErrorCode != 0 {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			if used != tt.expectedRenderer {
+				t.Errorf("expected renderer %q, got %q", tt.expectedRenderer, used)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/builder"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func TestApiConvertSniffDocument(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		expectedStatus  int
+		expectedCode    apiV1.ErrorCode
+		expectedMessage string
+	}{
+		{
+			name:            "Empty Upload Test",
+			content:         "",
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    apiV1.ErrCodeEmptyDocument,
+			expectedMessage: "Document is empty",
+		},
+		{
+			name:            "PNG Upload Test",
+			content:         "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedCode:    apiV1.ErrCodeUnsupportedDocument,
+			expectedMessage: "Document is not a PDF: detected image/png",
+		},
+		{
+			name:            "HTML Polyglot Upload Test",
+			content:         "<html><!-- %PDF-1.5 --></html>",
+			expectedStatus:  http.StatusUnsupportedMediaType,
+			expectedCode:    apiV1.ErrCodeUnsupportedDocument,
+			expectedMessage: "Document is not a PDF: text/html; charset=utf-8 with an embedded PDF header",
+		},
+	}
+
+	for _, path := range []string{"/v1/convert", "/v2/convert"} {
+		for _, tt := range tests {
+			t.Run(path+" "+tt.name, func(t *testing.T) {
+				convertUsecase := usecase.NewConvertUsecase(
+					builder.NewFileBuilder("qpdf"),
+					&MockImageConvertService{},
+					NewMockPdfDecryptService(false),
+					&MockPdfPageCountService{},
+					&MockPdfTextService{},
+					metrics.New(),
+					usecase.ConvertLimits{},
+				)
+				server := newTestServer(convertUsecase)
+
+				recorder := httptest.NewRecorder()
+				server.ServeHTTP(recorder, newConvertRequestWithContent(t, path, tt.content))
+
+				if recorder.Code != tt.expectedStatus {
+					t.Fatalf("expected status code %d, got %d", tt.expectedStatus, recorder.Code)
+				}
+
+				var resp apiV1.Error
+				if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+
+				if resp.Code != tt.expectedCode {
+					t.Errorf("expected error code %d, got %d", tt.expectedCode, resp.Code)
+				}
+
+				if resp.Message != tt.expectedMessage {
+					t.Errorf("expected message %q, got %q", tt.expectedMessage, resp.Message)
+				}
+			})
+		}
+	}
+}
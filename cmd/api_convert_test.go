@@ -3,17 +3,27 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/elct9620/pdf64/internal/app"
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/controller/health"
 	v1 "github.com/elct9620/pdf64/internal/controller/v1"
+	v2 "github.com/elct9620/pdf64/internal/controller/v2"
 	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/repository"
+	"github.com/elct9620/pdf64/internal/service"
 	"github.com/elct9620/pdf64/internal/usecase"
 	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
 	"github.com/google/uuid"
@@ -22,19 +32,57 @@ import (
 // MockImageConvertService is a mock implementation of the ImageConvertService interface
 type MockImageConvertService struct{}
 
-// Convert always returns a successful result with a mock base64 image
-func (m *MockImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]string, error) {
-	// Return a mock base64 encoded image
-	return []string{"data:image/jpeg;base64,/9j/4AAQSkZJRgABAQEAYABgAAD/2wBDAAgGBgcGBQgHBwcJCQgKDBQNDAsLDBkSEw8UHRofHh0aHBwgJC4nICIsIxwcKDcpLDAxNDQ0Hyc5PTgyPC4zNDL/2wBDAQkJCQwLDBgNDRgyIRwhMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjL/wAARCAABAAEDASIAAhEBAxEB/8QAHwAAAQUBAQEBAQEAAAAAAAAAAAECAwQFBgcICQoL/8QAtRAAAgEDAwIEAwUFBAQAAAF9AQIDAAQRBRIhMUEGE1FhByJxFDKBkaEII0KxwRVS0fAkM2JyggkKFhcYGRolJicoKSo0NTY3ODk6Q0RFRkdISUpTVFVWV1hZWmNkZWZnaGlqc3R1dnd4eXqDhIWGh4iJipKTlJWWl5iZmqKjpKWmp6ipqrKztLW2t7i5usLDxMXGx8jJytLT1NXW19jZ2uHi4+Tl5ufo6erx8vP09fb3+Pn6/8QAHwEAAwEBAQEBAQEBAQAAAAAAAAECAwQFBgcICQoL/8QAtREAAgECBAQDBAcFBAQAAQJ3AAECAxEEBSExBhJBUQdhcRMiMoEIFEKRobHBCSMzUvAVYnLRChYkNOEl8RcYGRomJygpKjU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6goOEhYaHiImKkpOUlZaXmJmaoqOkpaanqKmqsrO0tba3uLm6wsPExcbHyMnK0tPU1dbX2Nna4uPk5ebn6Onq8vP09fb3+Pn6/9oADAMBAAIRAxEAPwD3+iiigD//2Q=="}, nil
+// Convert returns a mock base64 image for each requested page, or a single one when merged
+func (m *MockImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	count := len(options.Pages)
+	if options.Merge || count == 0 {
+		count = 1
+	}
+
+	width, height := 1, 1
+	if options.Width > 0 {
+		width = options.Width
+	}
+	if options.Height > 0 {
+		height = options.Height
+	}
+
+	data, err := base64.StdEncoding.DecodeString(mockImageData)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]usecase.Image, count)
+	for i := range images {
+		page := i + 1
+		if i < len(options.Pages) {
+			page = options.Pages[i]
+		}
+		if options.Merge {
+			page = 0
+		}
+
+		images[i] = usecase.Image{
+			Page:     page,
+			Width:    width,
+			Height:   height,
+			MimeType: options.Format.MimeType(),
+			Data:     data,
+		}
+	}
+	return images, nil
 }
 
+// mockImageData is a base64 encoded 1x1 JPEG image
+const mockImageData = "/9j/4AAQSkZJRgABAQEAYABgAAD/2wBDAAgGBgcGBQgHBwcJCQgKDBQNDAsLDBkSEw8UHRofHh0aHBwgJC4nICIsIxwcKDcpLDAxNDQ0Hyc5PTgyPC4zNDL/2wBDAQkJCQwLDBgNDRgyIRwhMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjL/wAARCAABAAEDASIAAhEBAxEB/8QAHwAAAQUBAQEBAQEAAAAAAAAAAAECAwQFBgcICQoL/8QAtRAAAgEDAwIEAwUFBAQAAAF9AQIDAAQRBRIhMUEGE1FhByJxFDKBkaEII0KxwRVS0fAkM2JyggkKFhcYGRolJicoKSo0NTY3ODk6Q0RFRkdISUpTVFVWV1hZWmNkZWZnaGlqc3R1dnd4eXqDhIWGh4iJipKTlJWWl5iZmqKjpKWmp6ipqrKztLW2t7i5usLDxMXGx8jJytLT1NXW19jZ2uHi4+Tl5ufo6erx8vP09fb3+Pn6/8QAHwEAAwEBAQEBAQEBAQAAAAAAAAECAwQFBgcICQoL/8QAtREAAgECBAQDBAcFBAQAAQJ3AAECAxEEBSExBhJBUQdhcRMiMoEIFEKRobHBCSMzUvAVYnLRChYkNOEl8RcYGRomJygpKjU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6goOEhYaHiImKkpOUlZaXmJmaoqOkpaanqKmqsrO0tba3uLm6wsPExcbHyMnK0tPU1dbX2Nna4uPk5ebn6Onq8vP09fb3+Pn6/9oADAMBAAIRAxEAPwD3+iiigD//2Q=="
+
 // MockFileBuilder is a mock implementation of the usecase.FileBuilder interface
 type MockFileBuilder struct {
 	isEncrypted bool
 }
 
 // BuildFromPath returns a file that is encrypted based on isEncrypted flag
-func (m *MockFileBuilder) BuildFromPath(path string) (*entity.File, error) {
+func (m *MockFileBuilder) BuildFromPath(ctx context.Context, path string) (*entity.File, error) {
 	id, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
@@ -70,6 +118,80 @@ func (m *MockPdfDecryptService) Decrypt(ctx context.Context, file *entity.File,
 	return nil
 }
 
+// MockPdfPageCountService is a mock implementation of the PdfPageCountService interface
+type MockPdfPageCountService struct {
+	pageCount int
+}
+
+// PageCount returns the configured page count, defaulting to a single page
+func (m *MockPdfPageCountService) PageCount(ctx context.Context, file *entity.File) (int, error) {
+	if m.pageCount == 0 {
+		return 1, nil
+	}
+	return m.pageCount, nil
+}
+
+// newTestServer creates a server with in-memory jobs around the conversion usecase
+func newTestServer(convertUsecase *usecase.ConvertUsecase) *app.Server {
+	return newTestServerWithConfig(config.Default(), convertUsecase)
+}
+
+// newTestServerWithConfig creates a test server with the defaults and limits
+// of the config, /readyz reports the given checks
+func newTestServerWithConfig(cfg *config.Config, convertUsecase *usecase.ConvertUsecase, checks ...usecase.DependencyCheck) *app.Server {
+	return newTestServerWithMetrics(cfg, metrics.New(), convertUsecase, checks...)
+}
+
+// newTestServerWithMetrics creates a test server exposing the given metrics
+func newTestServerWithMetrics(cfg *config.Config, metrics *metrics.Metrics, convertUsecase *usecase.ConvertUsecase, checks ...usecase.DependencyCheck) *app.Server {
+	infoUsecase := usecase.NewInfoUsecase(&MockFileBuilder{}, NewMockPdfDecryptService(false), &MockPdfInfoService{})
+	return newTestServerWithInfo(cfg, metrics, convertUsecase, infoUsecase, checks...)
+}
+
+// newTestServerWithInfo creates a test server describing documents with the
+// info usecase
+func newTestServerWithInfo(cfg *config.Config, metrics *metrics.Metrics, convertUsecase *usecase.ConvertUsecase, infoUsecase *usecase.InfoUsecase, checks ...usecase.DependencyCheck) *app.Server {
+	jobRepository := repository.NewMemoryJobRepository(time.Minute)
+	webhookUsecase := usecase.NewWebhookUsecase(
+		jobRepository,
+		service.NewHttpWebhookNotifier(service.NewGuardedHttpClient(service.GuardedHttpClientOptions{
+			Timeout:         5 * time.Second,
+			AllowedNetworks: testFetchNetworks,
+		}), testWebhookSecret),
+		controller.NewWebhookPresenter(),
+		usecase.WebhookRetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+		},
+	)
+	jobUsecase := usecase.NewJobUsecase(
+		convertUsecase,
+		webhookUsecase,
+		jobRepository,
+		service.NewWorkerPoolJobQueue(1, 10, time.Second),
+	)
+
+	documentFetcher := service.NewHttpDocumentFetcher(service.HttpDocumentFetcherOptions{
+		MaxBytes:        1 << 20,
+		Timeout:         5 * time.Second,
+		AllowedNetworks: testFetchNetworks,
+	})
+	inputBuilder := controller.NewInputBuilder(documentFetcher, controller.ConvertDefaults{
+		Density: cfg.Defaults.Density,
+		Quality: cfg.Defaults.Quality,
+		Format:  cfg.Defaults.Format,
+	}, cfg.TempDir)
+
+	return app.NewServer(
+		cfg,
+		v1.NewService(convertUsecase, jobUsecase, infoUsecase, inputBuilder),
+		v2.NewService(convertUsecase, inputBuilder),
+		health.NewService(usecase.NewReadinessUsecase(checks...)),
+		metrics,
+	)
+}
+
 func TestApiV1Convert(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -77,6 +199,12 @@ func TestApiV1Convert(t *testing.T) {
 		quality           string
 		password          string
 		merge             string
+		pages             string
+		pageCount         int
+		format            string
+		width             string
+		height            string
+		maxPixels         string
 		fileContent       string
 		isEncrypted       bool
 		requirePassword   bool
@@ -138,19 +266,23 @@ func TestApiV1Convert(t *testing.T) {
 			expectedErrorCode: apiV1.ErrCodePasswordRequired,
 		},
 		{
-			name:            "Invalid Quality Parameter Test",
-			density:         "300",
-			quality:         "invalid",
-			password:        "",
-			fileContent:     "Test PDF Content",
-			isEncrypted:     false,
-			requirePassword: false,
-			expectedStatus:  http.StatusOK,
-			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
-				if resp.Id == "" {
-					t.Errorf("expected non-empty ID, got empty string")
-				}
-			},
+			name:              "Invalid Quality Parameter Test",
+			density:           "300",
+			quality:           "invalid",
+			password:          "",
+			fileContent:       "Test PDF Content",
+			isEncrypted:       false,
+			requirePassword:   false,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidParameter,
+		},
+		{
+			name:              "Out Of Range Quality Parameter Test",
+			density:           "300",
+			quality:           "101",
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidParameter,
 		},
 		{
 			name:            "Merge Pages Test",
@@ -194,6 +326,137 @@ func TestApiV1Convert(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:           "Page Range Test",
+			density:        "300",
+			quality:        "90",
+			pages:          "1-3,7,9-",
+			pageCount:      10,
+			fileContent:    "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				expectedPages := []int{1, 2, 3, 7, 9, 10}
+				if !slices.Equal(resp.Pages, expectedPages) {
+					t.Errorf("expected pages %v, got %v", expectedPages, resp.Pages)
+				}
+
+				if len(resp.Data) != len(expectedPages) {
+					t.Errorf("expected %d images, got %d", len(expectedPages), len(resp.Data))
+				}
+			},
+		},
+		{
+			name:           "All Pages By Default Test",
+			density:        "300",
+			quality:        "90",
+			pageCount:      3,
+			fileContent:    "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				expectedPages := []int{1, 2, 3}
+				if !slices.Equal(resp.Pages, expectedPages) {
+					t.Errorf("expected pages %v, got %v", expectedPages, resp.Pages)
+				}
+			},
+		},
+		{
+			name:              "Page Range Out Of Bounds Test",
+			density:           "300",
+			quality:           "90",
+			pages:             "5-12",
+			pageCount:         10,
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidPageRange,
+		},
+		{
+			name:              "Malformed Page Range Test",
+			density:           "300",
+			quality:           "90",
+			pages:             "3-1",
+			pageCount:         10,
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidPageRange,
+		},
+		{
+			name:           "Default Format Test",
+			density:        "300",
+			quality:        "90",
+			fileContent:    "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				for i, item := range resp.Data {
+					if !strings.HasPrefix(item, "data:image/jpeg;base64,") {
+						t.Errorf("expected Data[%d] to be a JPEG data URI, got: %.32s", i, item)
+					}
+				}
+			},
+		},
+		{
+			name:           "PNG Format Test",
+			density:        "300",
+			quality:        "90",
+			format:         "PNG",
+			fileContent:    "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				for i, item := range resp.Data {
+					if !strings.HasPrefix(item, "data:image/png;base64,") {
+						t.Errorf("expected Data[%d] to be a PNG data URI, got: %.32s", i, item)
+					}
+				}
+			},
+		},
+		{
+			name:              "Unsupported Format Test",
+			density:           "300",
+			quality:           "90",
+			format:            "bmp",
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeUnsupportedFormat,
+		},
+		{
+			name:           "Resize Test",
+			density:        "300",
+			quality:        "90",
+			width:          "200",
+			height:         "300",
+			maxPixels:      "60000",
+			pageCount:      2,
+			fileContent:    "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				if len(resp.Sizes) != len(resp.Data) {
+					t.Fatalf("expected %d sizes, got %d", len(resp.Data), len(resp.Sizes))
+				}
+
+				for i, size := range resp.Sizes {
+					if size.Width != 200 || size.Height != 300 {
+						t.Errorf("expected Sizes[%d] to be 200x300, got %dx%d", i, size.Width, size.Height)
+					}
+				}
+			},
+		},
+		{
+			name:              "Negative Size Test",
+			density:           "300",
+			quality:           "90",
+			width:             "-1",
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidParameter,
+		},
+		{
+			name:              "Non-numeric Size Test",
+			density:           "300",
+			quality:           "90",
+			maxPixels:         "large",
+			fileContent:       "%PDF-1.5\n%%EOF\n", // Minimal valid PDF structure
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidParameter,
+		},
 	}
 
 	for _, tt := range tests {
@@ -207,10 +470,10 @@ func TestApiV1Convert(t *testing.T) {
 			// Create mock services
 			mockImageConvertService := &MockImageConvertService{}
 			mockPdfDecryptService := NewMockPdfDecryptService(tt.requirePassword)
+			mockPdfPageCountService := &MockPdfPageCountService{pageCount: tt.pageCount}
 
-			convertUsecase := usecase.NewConvertUsecase(fileBuilder, mockImageConvertService, mockPdfDecryptService)
-			apiV1Service := v1.NewService(convertUsecase)
-			server := app.NewServer(apiV1Service)
+			convertUsecase := usecase.NewConvertUsecase(fileBuilder, mockImageConvertService, mockPdfDecryptService, mockPdfPageCountService, &MockPdfTextService{}, metrics.New(), usecase.ConvertLimits{})
+			server := newTestServer(convertUsecase)
 
 			body := &bytes.Buffer{}
 			writer := multipart.NewWriter(body)
@@ -223,6 +486,21 @@ func TestApiV1Convert(t *testing.T) {
 			if tt.merge != "" {
 				_ = writer.WriteField("merge", tt.merge)
 			}
+			if tt.pages != "" {
+				_ = writer.WriteField("pages", tt.pages)
+			}
+			if tt.format != "" {
+				_ = writer.WriteField("format", tt.format)
+			}
+			if tt.width != "" {
+				_ = writer.WriteField("width", tt.width)
+			}
+			if tt.height != "" {
+				_ = writer.WriteField("height", tt.height)
+			}
+			if tt.maxPixels != "" {
+				_ = writer.WriteField("max_pixels", tt.maxPixels)
+			}
 
 			part, err := writer.CreateFormFile("data", "test.pdf")
 			if err != nil {
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// testFetchNetworks allows the test server to fetch from local httptest servers
+var testFetchNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+func TestApiV1ConvertURL(t *testing.T) {
+	document := "%PDF-1.5\n%%EOF\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/document.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, document)
+	})
+	mux.HandleFunc("/large.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, strings.Repeat("0", 2<<20))
+	})
+	origin := httptest.NewServer(mux)
+	defer origin.Close()
+
+	documentURL := origin.URL + "/document.pdf"
+
+	tests := []struct {
+		name              string
+		contentType       string
+		body              string
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+	}{
+		{
+			name:           "Multipart URL Test",
+			contentType:    "multipart",
+			body:           documentURL,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "URL-encoded URL Test",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           url.Values{"url": {documentURL}}.Encode(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "JSON URL Test",
+			contentType:    "application/json",
+			body:           `{"url":"` + documentURL + `"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:              "Both File And URL Test",
+			contentType:       "application/json",
+			body:              `{"url":"` + documentURL + `","data":"JVBERi0xLjUK"}`,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+		{
+			name:              "Missing Source Test",
+			contentType:       "application/x-www-form-urlencoded",
+			body:              url.Values{"density": {"300"}}.Encode(),
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+		{
+			name:              "Forbidden Scheme Test",
+			contentType:       "multipart",
+			body:              "file:///etc/passwd",
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeFetchForbidden,
+		},
+		{
+			name:              "Missing Document Test",
+			contentType:       "multipart",
+			body:              origin.URL + "/missing.pdf",
+			expectedStatus:    http.StatusBadGateway,
+			expectedErrorCode: apiV1.ErrCodeFetchFailed,
+		},
+		{
+			name:              "Large Document Test",
+			contentType:       "multipart",
+			body:              origin.URL + "/large.pdf",
+			expectedStatus:    http.StatusRequestEntityTooLarge,
+			expectedErrorCode: apiV1.ErrCodeMaxFileSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileBuilder := &MockRecordingFileBuilder{}
+			convertUsecase := usecase.NewConvertUsecase(
+				fileBuilder,
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			// The multipart body sends the url as a form field
+			body := bytes.NewBufferString(tt.body)
+			contentType := tt.contentType
+			if contentType == "multipart" {
+				body = &bytes.Buffer{}
+				writer := multipart.NewWriter(body)
+				if err := writer.WriteField("url", tt.body); err != nil {
+					t.Fatal(err)
+				}
+				if err := writer.Close(); err != nil {
+					t.Fatal(err)
+				}
+				contentType = writer.FormDataContentType()
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", body)
+			req.Header.Set("Content-Type", contentType)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			if string(fileBuilder.content) != document {
+				t.Errorf("expected fetched document %q, got %q", document, fileBuilder.content)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// MockPdfInfoService describes every document as a two pages PDF 1.7, or
+// fails with err when set
+type MockPdfInfoService struct {
+	err error
+}
+
+func (m *MockPdfInfoService) Info(ctx context.Context, file *entity.File) (*usecase.DocumentInfo, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &usecase.DocumentInfo{
+		PdfVersion: "1.7",
+		Metadata: usecase.DocumentMetadata{
+			Title:    "Report",
+			Creator:  "Writer",
+			Producer: "LibreOffice",
+		},
+		Pages: []usecase.PageInfo{
+			{Number: 1, Width: 612, Height: 792, HasText: true},
+			{Number: 2, Width: 792, Height: 612, Rotation: 90},
+		},
+	}, nil
+}
+
+// MockErrorPdfDecryptService fails every decryption with err
+type MockErrorPdfDecryptService struct {
+	err error
+}
+
+func (m *MockErrorPdfDecryptService) Decrypt(ctx context.Context, file *entity.File, password string) error {
+	return m.err
+}
+
+func TestApiV1Info(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.7\n%%EOF\n"))
+
+	tests := []struct {
+		name              string
+		body              any
+		isEncrypted       bool
+		decryptErr        error
+		infoErr           error
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		validateResp      func(t *testing.T, resp *apiV1.InfoResponse)
+	}{
+		{
+			name:           "Document Info Test",
+			body:           map[string]any{"data": encoded},
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.InfoResponse) {
+				if resp.PdfVersion != "1.7" || resp.PageCount != 2 || resp.IsEncrypted {
+					t.Errorf("unexpected document info %+v", resp)
+				}
+
+				if resp.Metadata.Producer != "LibreOffice" || resp.Metadata.Creator != "Writer" {
+					t.Errorf("unexpected metadata %+v", resp.Metadata)
+				}
+
+				expected := []apiV1.PageInfo{
+					{Page: 1, Width: 612, Height: 792, HasText: true},
+					{Page: 2, Width: 792, Height: 612, Rotation: 90},
+				}
+				for i, page := range resp.Pages {
+					if page != expected[i] {
+						t.Errorf("expected page %+v, got %+v", expected[i], page)
+					}
+				}
+			},
+		},
+		{
+			name:           "Encrypted Document Test",
+			body:           map[string]any{"data": encoded, "password": "secret123"},
+			isEncrypted:    true,
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.InfoResponse) {
+				if !resp.IsEncrypted {
+					t.Error("expected the document to be reported as encrypted")
+				}
+			},
+		},
+		{
+			name:           "Encrypted Without Password Test",
+			body:           map[string]any{"data": encoded},
+			isEncrypted:    true,
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV1.InfoResponse) {
+				if !resp.IsEncrypted || resp.PageCount != 0 || resp.Pages == nil || len(resp.Pages) != 0 {
+					t.Errorf("expected only the encryption to be reported, got %+v", resp)
+				}
+			},
+		},
+		{
+			name:              "Invalid Password Test",
+			body:              map[string]any{"data": encoded, "password": "wrong"},
+			isEncrypted:       true,
+			decryptErr:        usecase.ErrInvalidPassword,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidPassword,
+		},
+		{
+			name:              "Corrupt Document Test",
+			body:              map[string]any{"data": encoded},
+			infoErr:           usecase.ErrCorruptDocument,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedErrorCode: apiV1.ErrCodeCorruptDocument,
+		},
+		{
+			name:              "Missing Document Test",
+			body:              map[string]any{},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decrypter usecase.PdfDecryptService = NewMockPdfDecryptService(false)
+			if tt.decryptErr != nil {
+				decrypter = &MockErrorPdfDecryptService{err: tt.decryptErr}
+			}
+
+			infoUsecase := usecase.NewInfoUsecase(
+				&MockFileBuilder{isEncrypted: tt.isEncrypted},
+				decrypter,
+				&MockPdfInfoService{err: tt.infoErr},
+			)
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServerWithInfo(config.Default(), metrics.New(), convertUsecase, infoUsecase)
+
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/info", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedErrorCode != 0 {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			var resp apiV1.InfoResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if tt.validateResp != nil {
+				tt.validateResp(t, &resp)
+			}
+		})
+	}
+}
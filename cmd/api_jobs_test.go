@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/app"
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// MockBlockingImageConvertService blocks until released, started is
+// notified when set
+type MockBlockingImageConvertService struct {
+	MockImageConvertService
+	started chan struct{}
+	release chan struct{}
+}
+
+// Convert waits for the release before returning the mock images
+func (m *MockBlockingImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	if m.started != nil {
+		m.started <- struct{}{}
+	}
+
+	select {
+	case <-m.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return m.MockImageConvertService.Convert(ctx, file, options)
+}
+
+func TestApiV1Jobs(t *testing.T) {
+	tests := []struct {
+		name                 string
+		converter            usecase.ImageConvertService
+		isEncrypted          bool
+		pageCount            int
+		expectedSubmitStatus int
+		expectedJobStatus    apiV1.JobStatus
+		expectedResultStatus int
+		expectedErrorCode    apiV1.ErrorCode
+		validateResult       func(t *testing.T, resp *apiV1.ConvertResponse)
+	}{
+		{
+			name:                 "Succeeded Job Test",
+			converter:            &MockImageConvertService{},
+			pageCount:            3,
+			expectedSubmitStatus: http.StatusAccepted,
+			expectedJobStatus:    apiV1.JobStatusSucceeded,
+			expectedResultStatus: http.StatusOK,
+			validateResult: func(t *testing.T, resp *apiV1.ConvertResponse) {
+				if len(resp.Data) != 3 {
+					t.Errorf("expected 3 images, got %d", len(resp.Data))
+				}
+			},
+		},
+		{
+			name:                 "Failed Job Test",
+			converter:            &MockFailingImageConvertService{failOnPage: 1},
+			pageCount:            1,
+			expectedSubmitStatus: http.StatusAccepted,
+			expectedJobStatus:    apiV1.JobStatusFailed,
+			expectedResultStatus: http.StatusInternalServerError,
+			expectedErrorCode:    apiV1.ErrCodeInternal,
+		},
+		{
+			name:                 "Running Job Test",
+			converter:            &MockBlockingImageConvertService{release: make(chan struct{})},
+			pageCount:            1,
+			expectedSubmitStatus: http.StatusAccepted,
+			expectedResultStatus: http.StatusConflict,
+			expectedErrorCode:    apiV1.ErrCodeJobNotFinished,
+		},
+		{
+			name:                 "Rejected Job Test",
+			converter:            &MockImageConvertService{},
+			isEncrypted:          true,
+			expectedSubmitStatus: http.StatusBadRequest,
+			expectedErrorCode:    apiV1.ErrCodePasswordRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{isEncrypted: tt.isEncrypted},
+				tt.converter,
+				NewMockPdfDecryptService(true),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			if blocking, ok := tt.converter.(*MockBlockingImageConvertService); ok {
+				defer close(blocking.release)
+			}
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/jobs", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedSubmitStatus {
+				t.Fatalf("expected status code %d, got %d", tt.expectedSubmitStatus, recorder.Code)
+			}
+
+			if tt.expectedSubmitStatus != http.StatusAccepted {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			var job apiV1.JobResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &job); err != nil {
+				t.Fatalf("failed to unmarshal job response: %v", err)
+			}
+
+			if location := recorder.Header().Get("Location"); location != "/v1/jobs/"+job.Id {
+				t.Errorf("expected Location to be /v1/jobs/%s, got %s", job.Id, location)
+			}
+
+			if tt.expectedJobStatus != "" {
+				job = waitForJob(t, server, job.Id)
+				if job.Status != tt.expectedJobStatus {
+					t.Errorf("expected job status %s, got %s", tt.expectedJobStatus, job.Status)
+				}
+
+				if job.Status == apiV1.JobStatusFailed && (job.Error == nil || job.Error.Code != tt.expectedErrorCode) {
+					t.Errorf("expected job error code %d, got %+v", tt.expectedErrorCode, job.Error)
+				}
+			}
+
+			req = httptest.NewRequest("GET", "/v1/jobs/"+job.Id+"/result", nil)
+			recorder = httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedResultStatus {
+				t.Fatalf("expected result status code %d, got %d", tt.expectedResultStatus, recorder.Code)
+			}
+
+			if tt.expectedResultStatus != http.StatusOK {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			var resp apiV1.ConvertResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal result: %v", err)
+			}
+
+			if resp.Id != job.Id {
+				t.Errorf("expected result id %s, got %s", job.Id, resp.Id)
+			}
+
+			if tt.validateResult != nil {
+				tt.validateResult(t, &resp)
+			}
+		})
+	}
+}
+
+func TestApiV1JobNotFound(t *testing.T) {
+	convertUsecase := usecase.NewConvertUsecase(
+		&MockFileBuilder{},
+		&MockImageConvertService{},
+		NewMockPdfDecryptService(false),
+		&MockPdfPageCountService{},
+		&MockPdfTextService{},
+		metrics.New(),
+		usecase.ConvertLimits{},
+	)
+	server := newTestServer(convertUsecase)
+
+	for _, path := range []string{"/v1/jobs/unknown", "/v1/jobs/unknown/result"} {
+		req := httptest.NewRequest("GET", path, nil)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d for %s, got %d", http.StatusNotFound, path, recorder.Code)
+		}
+
+		assertErrorCode(t, recorder.Body.Bytes(), apiV1.ErrCodeJobNotFound)
+	}
+}
+
+// waitForJob polls the job until it is finished
+func waitForJob(t *testing.T, server *app.Server, id string) apiV1.JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		req := httptest.NewRequest("GET", "/v1/jobs/"+id, nil)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d", http.StatusOK, recorder.Code)
+		}
+
+		var job apiV1.JobResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &job); err != nil {
+			t.Fatalf("failed to unmarshal job response: %v", err)
+		}
+
+		if job.Status == apiV1.JobStatusSucceeded || job.Status == apiV1.JobStatusFailed {
+			return job
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s did not finish, last status %s", id, job.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
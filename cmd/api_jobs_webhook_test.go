@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/app"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+const testWebhookSecret = "test-secret"
+
+// webhookReceiver records the callbacks and fails the first attempts
+type webhookReceiver struct {
+	mutex      sync.Mutex
+	failures   int
+	attempts   int
+	callback   *apiV1.JobCallback
+	signature  string
+	body       []byte
+	isReceived chan struct{}
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	r.body, _ = io.ReadAll(req.Body)
+	r.signature = req.Header.Get(service.SignatureHeader)
+
+	var callback apiV1.JobCallback
+	if err := json.Unmarshal(r.body, &callback); err == nil {
+		r.callback = &callback
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	close(r.isReceived)
+}
+
+func TestApiV1JobsWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		converter          usecase.ImageConvertService
+		failures           int
+		expectedStatus     apiV1.JobStatus
+		expectedDeliveries int
+		expectedErrorCode  apiV1.ErrorCode
+	}{
+		{
+			name:               "Succeeded Job Callback Test",
+			converter:          &MockImageConvertService{},
+			expectedStatus:     apiV1.JobStatusSucceeded,
+			expectedDeliveries: 1,
+		},
+		{
+			name:               "Failed Job Callback Test",
+			converter:          &MockFailingImageConvertService{failOnPage: 1},
+			expectedStatus:     apiV1.JobStatusFailed,
+			expectedDeliveries: 1,
+			expectedErrorCode:  apiV1.ErrCodeInternal,
+		},
+		{
+			name:               "Retried Callback Test",
+			converter:          &MockImageConvertService{},
+			failures:           2,
+			expectedStatus:     apiV1.JobStatusSucceeded,
+			expectedDeliveries: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{failures: tt.failures, isReceived: make(chan struct{})}
+			receiverServer := httptest.NewServer(receiver)
+			defer receiverServer.Close()
+
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				tt.converter,
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			recorder := submitJob(t, server, receiverServer.URL)
+			if recorder.Code != http.StatusAccepted {
+				t.Fatalf("expected status code %d, got %d", http.StatusAccepted, recorder.Code)
+			}
+
+			var job apiV1.JobResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &job); err != nil {
+				t.Fatalf("failed to unmarshal job response: %v", err)
+			}
+
+			select {
+			case <-receiver.isReceived:
+			case <-time.After(5 * time.Second):
+				t.Fatal("webhook was not delivered")
+			}
+
+			receiver.mutex.Lock()
+			callback, signature, body := receiver.callback, receiver.signature, receiver.body
+			receiver.mutex.Unlock()
+
+			if expected := "sha256=" + service.Sign([]byte(testWebhookSecret), body); signature != expected {
+				t.Errorf("expected signature %s, got %s", expected, signature)
+			}
+
+			if callback == nil {
+				t.Fatal("failed to unmarshal callback payload")
+			}
+
+			if callback.Id != job.Id || callback.Status != tt.expectedStatus {
+				t.Errorf("expected callback for job %s with status %s, got %s with %s", job.Id, tt.expectedStatus, callback.Id, callback.Status)
+			}
+
+			if tt.expectedStatus == apiV1.JobStatusSucceeded && (callback.Result == nil || len(callback.Result.Data) != 1) {
+				t.Errorf("expected callback result with 1 image, got %+v", callback.Result)
+			}
+
+			if tt.expectedStatus == apiV1.JobStatusFailed && (callback.Error == nil || callback.Error.Code != tt.expectedErrorCode) {
+				t.Errorf("expected callback error code %d, got %+v", tt.expectedErrorCode, callback.Error)
+			}
+
+			job = waitForDeliveries(t, server, job.Id, tt.expectedDeliveries)
+			last := job.Deliveries[len(job.Deliveries)-1]
+			if last.StatusCode != http.StatusNoContent || last.Error != "" {
+				t.Errorf("expected last delivery to succeed, got %+v", last)
+			}
+
+			for i, delivery := range job.Deliveries[:len(job.Deliveries)-1] {
+				if delivery.Attempt != i+1 || delivery.StatusCode != http.StatusInternalServerError || delivery.Error == "" {
+					t.Errorf("expected attempt %d to fail, got %+v", i+1, delivery)
+				}
+			}
+		})
+	}
+}
+
+func TestApiV1JobsInvalidCallbackURL(t *testing.T) {
+	convertUsecase := usecase.NewConvertUsecase(
+		&MockFileBuilder{},
+		&MockImageConvertService{},
+		NewMockPdfDecryptService(false),
+		&MockPdfPageCountService{},
+		&MockPdfTextService{},
+		metrics.New(),
+		usecase.ConvertLimits{},
+	)
+	server := newTestServer(convertUsecase)
+
+	for _, callbackURL := range []string{"ftp://example.com/hook", "/relative/hook", "http://"} {
+		recorder := submitJob(t, server, callbackURL)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d for %q, got %d", http.StatusBadRequest, callbackURL, recorder.Code)
+		}
+
+		assertErrorCode(t, recorder.Body.Bytes(), apiV1.ErrCodeInvalidCallbackURL)
+	}
+}
+
+// submitJob posts a dummy document as a job with the callback URL
+func submitJob(t *testing.T, server *app.Server, callbackURL string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("data", "test.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.WriteField("callback_url", callbackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/v1/jobs", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+// waitForDeliveries polls the job until the expected delivery attempts are recorded
+func waitForDeliveries(t *testing.T, server *app.Server, id string, count int) apiV1.JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job := waitForJob(t, server, id)
+		if len(job.Deliveries) >= count {
+			if len(job.Deliveries) != count {
+				t.Fatalf("expected %d deliveries, got %d", count, len(job.Deliveries))
+			}
+			return job
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d deliveries, got %d", count, len(job.Deliveries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
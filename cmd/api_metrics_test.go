@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestMetrics(t *testing.T) {
+	tests := []struct {
+		name          string
+		hasFile       bool
+		pageCount     int
+		expectedLines []string
+	}{
+		{
+			name:      "Succeeded Conversion Test",
+			hasFile:   true,
+			pageCount: 3,
+			expectedLines: []string{
+				`pdf64_http_requests_total{error_code="none",method="POST",route="/v1/convert",status="200"} 1`,
+				`pdf64_pages_rendered_total 3`,
+				`pdf64_conversions_in_flight 0`,
+			},
+		},
+		{
+			name:    "Failed Conversion Test",
+			hasFile: false,
+			expectedLines: []string{
+				`pdf64_http_requests_total{error_code="2",method="POST",route="/v1/convert",status="400"} 1`,
+				`pdf64_pages_rendered_total 0`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverMetrics := metrics.New()
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				serverMetrics,
+				usecase.ConvertLimits{},
+			)
+			server := newTestServerWithMetrics(config.Default(), serverMetrics, convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			if tt.hasFile {
+				part, err := writer.CreateFormFile("data", "test.pdf")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, err := io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v1/convert", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			server.ServeHTTP(httptest.NewRecorder(), req)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, recorder.Code)
+			}
+
+			exposition := recorder.Body.String()
+			for _, line := range tt.expectedLines {
+				if !strings.Contains(exposition, line+"\n") {
+					t.Errorf("expected metrics to contain %q", line)
+				}
+			}
+
+			if strings.Contains(exposition, `route="/metrics"`) {
+				t.Error("expected the metrics endpoint not to be counted")
+			}
+		})
+	}
+}
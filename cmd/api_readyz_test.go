@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/elct9620/pdf64/pkg/apis/health"
+)
+
+type MockBoundedQueue struct {
+	length   int
+	capacity int
+	isClosed bool
+}
+
+func (m *MockBoundedQueue) Len() int       { return m.length }
+func (m *MockBoundedQueue) Cap() int       { return m.capacity }
+func (m *MockBoundedQueue) IsClosed() bool { return m.isClosed }
+
+// writeFakeBinary creates an executable script printing the version
+func writeFakeBinary(t *testing.T, version string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "gs")
+	script := "#!/bin/sh\necho '" + version + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name               string
+		checks             func(t *testing.T) []usecase.DependencyCheck
+		expectedStatusCode int
+		expectedStatuses   map[string]health.Status
+		expectedDetails    map[string]string
+	}{
+		{
+			name: "All Dependencies Ready Test",
+			checks: func(t *testing.T) []usecase.DependencyCheck {
+				return []usecase.DependencyCheck{
+					service.NewCommandCheck("ghostscript", time.Minute, []string{writeFakeBinary(t, "10.02.1")}, "--version"),
+					service.NewTempDirCheck(t.TempDir()),
+					service.NewQueueCheck("job_queue", &MockBoundedQueue{length: 1, capacity: 10}),
+				}
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedStatuses: map[string]health.Status{
+				"ghostscript": health.StatusReady,
+				"temp_dir":    health.StatusReady,
+				"job_queue":   health.StatusReady,
+			},
+			expectedDetails: map[string]string{
+				"ghostscript": "10.02.1",
+				"job_queue":   "1/10",
+			},
+		},
+		{
+			name: "Missing Binary Test",
+			checks: func(t *testing.T) []usecase.DependencyCheck {
+				return []usecase.DependencyCheck{
+					service.NewCommandCheck("ghostscript", time.Minute, []string{filepath.Join(t.TempDir(), "gs")}, "--version"),
+					service.NewTempDirCheck(t.TempDir()),
+				}
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatuses: map[string]health.Status{
+				"ghostscript": health.StatusUnavailable,
+				"temp_dir":    health.StatusReady,
+			},
+		},
+		{
+			name: "Unwritable Temp Directory Test",
+			checks: func(t *testing.T) []usecase.DependencyCheck {
+				return []usecase.DependencyCheck{
+					service.NewTempDirCheck(filepath.Join(t.TempDir(), "missing")),
+				}
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatuses: map[string]health.Status{
+				"temp_dir": health.StatusUnavailable,
+			},
+		},
+		{
+			name: "Saturated Queue Test",
+			checks: func(t *testing.T) []usecase.DependencyCheck {
+				return []usecase.DependencyCheck{
+					service.NewQueueCheck("job_queue", &MockBoundedQueue{length: 10, capacity: 10}),
+				}
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatuses: map[string]health.Status{
+				"job_queue": health.StatusUnavailable,
+			},
+		},
+		{
+			name: "Closed Queue Test",
+			checks: func(t *testing.T) []usecase.DependencyCheck {
+				return []usecase.DependencyCheck{
+					service.NewQueueCheck("job_queue", &MockBoundedQueue{capacity: 10, isClosed: true}),
+				}
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatuses: map[string]health.Status{
+				"job_queue": health.StatusUnavailable,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServerWithConfig(config.Default(), convertUsecase, tt.checks(t)...)
+
+			req := httptest.NewRequest("GET", "/readyz", nil)
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatusCode, recorder.Code, recorder.Body.String())
+			}
+
+			var resp health.ReadinessResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(resp.Dependencies) != len(tt.expectedStatuses) {
+				t.Fatalf("expected %d dependencies, got %d", len(tt.expectedStatuses), len(resp.Dependencies))
+			}
+
+			for _, dependency := range resp.Dependencies {
+				if dependency.Status != tt.expectedStatuses[dependency.Name] {
+					t.Errorf("expected %s to be %s, got %s", dependency.Name, tt.expectedStatuses[dependency.Name], dependency.Status)
+				}
+
+				if dependency.Status == health.StatusUnavailable && dependency.Error == "" {
+					t.Errorf("expected an error for unavailable %s", dependency.Name)
+				}
+
+				if expected, ok := tt.expectedDetails[dependency.Name]; ok && dependency.Detail != expected {
+					t.Errorf("expected %s detail %q, got %q", dependency.Name, expected, dependency.Detail)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	apiV2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+// MockPdfTextService returns "Text of page N" for each page, or fails with
+// err when set
+type MockPdfTextService struct {
+	err error
+}
+
+func (m *MockPdfTextService) Text(ctx context.Context, file *entity.File, pages []int) ([]usecase.PageText, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	texts := make([]usecase.PageText, 0, len(pages))
+	for _, page := range pages {
+		texts = append(texts, usecase.PageText{Page: page, Text: fmt.Sprintf("Text of page %d", page)})
+	}
+	return texts, nil
+}
+
+func newTextTestServer(textErr error) http.Handler {
+	convertUsecase := usecase.NewConvertUsecase(
+		&MockFileBuilder{},
+		&MockImageConvertService{},
+		NewMockPdfDecryptService(false),
+		&MockPdfPageCountService{pageCount: 3},
+		&MockPdfTextService{err: textErr},
+		metrics.New(),
+		usecase.ConvertLimits{},
+	)
+	return newTestServer(convertUsecase)
+}
+
+func postJSON(t *testing.T, server http.Handler, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", path, bytes.NewReader(encoded))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestApiV1Text(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.5\n%%EOF\n"))
+
+	tests := []struct {
+		name              string
+		body              map[string]any
+		textErr           error
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		expectedPages     []apiV1.PageText
+	}{
+		{
+			name:           "Every Page Test",
+			body:           map[string]any{"data": encoded},
+			expectedStatus: http.StatusOK,
+			expectedPages: []apiV1.PageText{
+				{Page: 1, Text: "Text of page 1"},
+				{Page: 2, Text: "Text of page 2"},
+				{Page: 3, Text: "Text of page 3"},
+			},
+		},
+		{
+			name:           "Selected Pages Test",
+			body:           map[string]any{"data": encoded, "pages": "1,3"},
+			expectedStatus: http.StatusOK,
+			expectedPages: []apiV1.PageText{
+				{Page: 1, Text: "Text of page 1"},
+				{Page: 3, Text: "Text of page 3"},
+			},
+		},
+		{
+			name:              "Invalid Page Range Test",
+			body:              map[string]any{"data": encoded, "pages": "5"},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeInvalidPageRange,
+		},
+		{
+			name:              "Corrupt Document Test",
+			body:              map[string]any{"data": encoded},
+			textErr:           usecase.ErrCorruptDocument,
+			expectedStatus:    http.StatusUnprocessableEntity,
+			expectedErrorCode: apiV1.ErrCodeCorruptDocument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := postJSON(t, newTextTestServer(tt.textErr), "/v1/text", tt.body)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", tt.expectedStatus, recorder.Code, recorder.Body.String())
+			}
+
+			if tt.expectedErrorCode != 0 {
+				assertErrorCode(t, recorder.Body.Bytes(), tt.expectedErrorCode)
+				return
+			}
+
+			var resp apiV1.TextResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if !slices.Equal(resp.Pages, tt.expectedPages) {
+				t.Errorf("expected pages %v, got %v", tt.expectedPages, resp.Pages)
+			}
+		})
+	}
+}
+
+func TestApiConvertIncludeText(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("%PDF-1.5\n%%EOF\n"))
+
+	t.Run("V1 Text Per Image Test", func(t *testing.T) {
+		recorder := postJSON(t, newTextTestServer(nil), "/v1/convert", map[string]any{"data": encoded, "pages": "2-3", "include_text": true})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status code 200, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+
+		var resp apiV1.ConvertResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		expected := []string{"Text of page 2", "Text of page 3"}
+		if !slices.Equal(resp.Text, expected) {
+			t.Errorf("expected text %q, got %q", expected, resp.Text)
+		}
+	})
+
+	t.Run("V1 Text Omitted Test", func(t *testing.T) {
+		recorder := postJSON(t, newTextTestServer(nil), "/v1/convert", map[string]any{"data": encoded})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status code 200, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+
+		var resp map[string]any
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		if _, ok := resp["text"]; ok {
+			t.Errorf("expected no text without include_text, got %v", resp["text"])
+		}
+	})
+
+	t.Run("V2 Merged Text Test", func(t *testing.T) {
+		recorder := postJSON(t, newTextTestServer(nil), "/v2/convert", map[string]any{"data": encoded, "merge": true, "include_text": true})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status code 200, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+
+		var resp apiV2.ConvertResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		expected := "Text of page 1\fText of page 2\fText of page 3"
+		if len(resp.Data) != 1 || resp.Data[0].Text == nil || *resp.Data[0].Text != expected {
+			t.Errorf("expected merged text %q, got %+v", expected, resp.Data)
+		}
+	})
+
+	t.Run("Text Failure Test", func(t *testing.T) {
+		recorder := postJSON(t, newTextTestServer(usecase.ErrCorruptDocument), "/v2/convert", map[string]any{"data": encoded, "include_text": true})
+		if recorder.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected status code 422, got %d: %s", recorder.Code, recorder.Body.String())
+		}
+
+		assertErrorCode(t, recorder.Body.Bytes(), apiV1.ErrCodeCorruptDocument)
+	})
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func findSpan(spans tracetest.SpanStubs, name string) *tracetest.SpanStub {
+	for i := range spans {
+		if spans[i].Name == name {
+			return &spans[i]
+		}
+	}
+	return nil
+}
+
+func findAttribute(span *tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		name              string
+		path              string
+		handlerSpan       string
+		usecaseSpan       string
+		pages             string
+		expectedPageCount int64
+		expectedErrorCode int64
+	}{
+		{
+			name:              "V1 Convert Test",
+			path:              "/v1/convert",
+			pages:             "1-2",
+			expectedPageCount: 2,
+		},
+		{
+			name:              "V2 Convert Test",
+			path:              "/v2/convert",
+			expectedPageCount: 3,
+		},
+		{
+			name:              "V2 Convert Stream Test",
+			path:              "/v2/convert/stream",
+			handlerSpan:       "PostConvertStream",
+			usecaseSpan:       "ConvertUsecase.Stream",
+			expectedPageCount: 3,
+		},
+		{
+			name:              "Invalid Page Range Test",
+			path:              "/v1/convert",
+			pages:             "5",
+			expectedErrorCode: 5,
+		},
+	}
+
+	// The global provider is bound to the package tracers only once, every
+	// case shares it and resets the recorded spans instead
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{},
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(false),
+				&MockPdfPageCountService{pageCount: 3},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n")); err != nil {
+				t.Fatal(err)
+			}
+			if tt.pages != "" {
+				if err := writer.WriteField("pages", tt.pages); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", tt.path, body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Traceparent", testTraceParent)
+			server.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			handlerSpan, usecaseSpan := "PostConvert", "ConvertUsecase.Execute"
+			if tt.handlerSpan != "" {
+				handlerSpan, usecaseSpan = tt.handlerSpan, tt.usecaseSpan
+			}
+
+			postConvert := findSpan(spans, handlerSpan)
+			execute := findSpan(spans, usecaseSpan)
+			if postConvert == nil || execute == nil {
+				t.Fatalf("expected %s and %s spans, got %d spans", handlerSpan, usecaseSpan, len(spans))
+			}
+
+			if traceId := postConvert.SpanContext.TraceID().String(); traceId != "4bf92f3577b34da6a3ce929d0e0e4736" {
+				t.Errorf("expected the trace of the traceparent header, got %s", traceId)
+			}
+
+			if execute.Parent.SpanID() != postConvert.SpanContext.SpanID() {
+				t.Errorf("expected %s to be a child of %s", usecaseSpan, handlerSpan)
+			}
+
+			if tt.expectedErrorCode != 0 {
+				if execute.Status.Code != codes.Error {
+					t.Errorf("expected failed %s span, got %v", usecaseSpan, execute.Status.Code)
+				}
+
+				if code, _ := findAttribute(postConvert, "pdf64.error_code"); code.AsInt64() != tt.expectedErrorCode {
+					t.Errorf("expected error code %d, got %v", tt.expectedErrorCode, code.Emit())
+				}
+				return
+			}
+
+			if pageCount, _ := findAttribute(execute, "pdf64.page_count"); pageCount.AsInt64() != tt.expectedPageCount {
+				t.Errorf("expected page count %d, got %v", tt.expectedPageCount, pageCount.Emit())
+			}
+
+			if density, ok := findAttribute(execute, "pdf64.density"); !ok || density.AsString() == "" {
+				t.Error("expected the density attribute")
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	apiV2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+// MockFailingImageConvertService fails when the given page is requested
+type MockFailingImageConvertService struct {
+	MockImageConvertService
+	failOnPage int
+}
+
+// Convert returns an error when the failing page is requested
+func (m *MockFailingImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	if slices.Contains(options.Pages, m.failOnPage) {
+		return nil, errors.New("mock conversion failure")
+	}
+	return m.MockImageConvertService.Convert(ctx, file, options)
+}
+
+func TestApiV2ConvertStream(t *testing.T) {
+	tests := []struct {
+		name                string
+		accept              string
+		fields              map[string]string
+		pageCount           int
+		failOnPage          int
+		isEncrypted         bool
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        func(t *testing.T, body string)
+	}{
+		{
+			name:                "NDJSON Stream Test",
+			fields:              map[string]string{"pages": "2-4"},
+			pageCount:           5,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: apiV2.ContentTypeNDJSON,
+			expectedBody: func(t *testing.T, body string) {
+				var pages []int
+				scanner := bufio.NewScanner(strings.NewReader(body))
+				scanner.Buffer(nil, 1<<20)
+				for scanner.Scan() {
+					var event apiV2.ImageEvent
+					if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+						t.Fatalf("failed to unmarshal event: %v", err)
+					}
+
+					if len(event.Id) < 32 {
+						t.Errorf("expected UUID format for Id, got: %s", event.Id)
+					}
+
+					pages = append(pages, event.Page)
+				}
+
+				if !slices.Equal(pages, []int{2, 3, 4}) {
+					t.Errorf("expected pages [2 3 4], got %v", pages)
+				}
+			},
+		},
+		{
+			name:                "Server-Sent Events Test",
+			accept:              "text/event-stream",
+			pageCount:           2,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: apiV2.ContentTypeEventStream,
+			expectedBody: func(t *testing.T, body string) {
+				if count := strings.Count(body, "event: page\ndata: "); count != 2 {
+					t.Errorf("expected 2 page events, got %d", count)
+				}
+
+				if !strings.Contains(body, "event: end\ndata: ") {
+					t.Errorf("expected end event, got: %s", body)
+				}
+			},
+		},
+		{
+			name:                "Merged Stream Test",
+			fields:              map[string]string{"merge": "true"},
+			pageCount:           3,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: apiV2.ContentTypeNDJSON,
+			expectedBody: func(t *testing.T, body string) {
+				if count := strings.Count(body, "\n"); count != 1 {
+					t.Errorf("expected a single merged event, got %d", count)
+				}
+			},
+		},
+		{
+			name:                "Error Before Stream Test",
+			isEncrypted:         true,
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/json",
+			expectedBody: func(t *testing.T, body string) {
+				var errorResp apiV1.Error
+				if err := json.Unmarshal([]byte(body), &errorResp); err != nil {
+					t.Fatalf("failed to unmarshal error response: %v", err)
+				}
+
+				if errorResp.Code != apiV1.ErrCodePasswordRequired {
+					t.Errorf("expected error code %d, got %d", apiV1.ErrCodePasswordRequired, errorResp.Code)
+				}
+			},
+		},
+		{
+			name:                "Error During Stream Test",
+			pageCount:           3,
+			failOnPage:          2,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: apiV2.ContentTypeNDJSON,
+			expectedBody: func(t *testing.T, body string) {
+				lines := strings.Split(strings.TrimSpace(body), "\n")
+				if len(lines) != 2 {
+					t.Fatalf("expected a page and an error line, got %d lines", len(lines))
+				}
+
+				var streamErr apiV2.StreamError
+				if err := json.Unmarshal([]byte(lines[1]), &streamErr); err != nil {
+					t.Fatalf("failed to unmarshal error line: %v", err)
+				}
+
+				if streamErr.Error.Code != apiV1.ErrCodeInternal {
+					t.Errorf("expected error code %d, got %d", apiV1.ErrCodeInternal, streamErr.Error.Code)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			convertUsecase := usecase.NewConvertUsecase(
+				&MockFileBuilder{isEncrypted: tt.isEncrypted},
+				&MockFailingImageConvertService{failOnPage: tt.failOnPage},
+				NewMockPdfDecryptService(true),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			for key, value := range tt.fields {
+				_ = writer.WriteField(key, value)
+			}
+
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v2/convert/stream", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatus, recorder.Code)
+			}
+
+			contentType := recorder.Header().Get("Content-Type")
+			if contentType != tt.expectedContentType {
+				t.Errorf("expected Content-Type to be %s, got %s", tt.expectedContentType, contentType)
+			}
+
+			tt.expectedBody(t, recorder.Body.String())
+		})
+	}
+}
+
+// MockBatchRecordingImageConvertService records the pages of each conversion
+type MockBatchRecordingImageConvertService struct {
+	MockImageConvertService
+	batches [][]int
+}
+
+func (m *MockBatchRecordingImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	m.batches = append(m.batches, options.Pages)
+	return m.MockImageConvertService.Convert(ctx, file, options)
+}
+
+// admissionRecorder records the admitted conversions at every write
+type admissionRecorder struct {
+	*httptest.ResponseRecorder
+	limited  *service.LimitedImageConvertService
+	admitted []int
+}
+
+func (r *admissionRecorder) Write(data []byte) (int, error) {
+	r.admitted = append(r.admitted, r.limited.Len())
+	return r.ResponseRecorder.Write(data)
+}
+
+func TestApiV2ConvertStreamAdmission(t *testing.T) {
+	converter := &MockBatchRecordingImageConvertService{}
+	limited := service.NewLimitedImageConvertService(converter, service.LimitedImageConvertOptions{
+		Concurrency:  2,
+		QueueSize:    0,
+		QueueTimeout: time.Second,
+		RetryAfter:   time.Second,
+	})
+	convertUsecase := usecase.NewConvertUsecase(
+		&MockFileBuilder{},
+		limited,
+		NewMockPdfDecryptService(false),
+		&MockPdfPageCountService{pageCount: 5},
+		&MockPdfTextService{},
+		metrics.New(),
+		usecase.ConvertLimits{StreamBatchSize: 2},
+	)
+	server := newTestServer(convertUsecase)
+
+	// Another request holds one of the two slots during the stream
+	_, release, err := limited.Admit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	recorder := &admissionRecorder{ResponseRecorder: httptest.NewRecorder(), limited: limited}
+	server.ServeHTTP(recorder, newConvertRequest(t, "/v2/convert/stream"))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if lines := strings.Count(recorder.Body.String(), "\n"); lines != 5 {
+		t.Errorf("expected 5 events, got %d", lines)
+	}
+
+	expectedBatches := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(converter.batches, expectedBatches, slices.Equal) {
+		t.Errorf("expected batches %v, got %v", expectedBatches, converter.batches)
+	}
+
+	for _, admitted := range recorder.admitted {
+		if admitted != 2 {
+			t.Fatalf("expected the stream to hold its slot until it ends, got %v admitted", recorder.admitted)
+		}
+	}
+
+	if limited.Len() != 1 {
+		t.Errorf("expected the stream to release its slot, got %d admitted", limited.Len())
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/usecase"
+	apiV1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	apiV2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+func TestApiV2Convert(t *testing.T) {
+	tests := []struct {
+		name              string
+		fields            map[string]string
+		pageCount         int
+		isEncrypted       bool
+		expectedStatus    int
+		expectedErrorCode apiV1.ErrorCode
+		validateResp      func(t *testing.T, resp *apiV2.ConvertResponse)
+	}{
+		{
+			name:           "Page Metadata Test",
+			fields:         map[string]string{"pages": "2-3", "format": "png", "width": "320", "height": "480"},
+			pageCount:      3,
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV2.ConvertResponse) {
+				if len(resp.Data) != 2 {
+					t.Fatalf("expected 2 images, got %d", len(resp.Data))
+				}
+
+				for i, image := range resp.Data {
+					if image.Page != i+2 {
+						t.Errorf("expected Data[%d].Page to be %d, got %d", i, i+2, image.Page)
+					}
+
+					if image.Width != 320 || image.Height != 480 {
+						t.Errorf("expected Data[%d] to be 320x480, got %dx%d", i, image.Width, image.Height)
+					}
+
+					if image.MimeType != "image/png" {
+						t.Errorf("expected Data[%d].MimeType to be image/png, got %s", i, image.MimeType)
+					}
+
+					if image.Bytes <= 0 {
+						t.Errorf("expected Data[%d].Bytes to be positive, got %d", i, image.Bytes)
+					}
+
+					if !strings.HasPrefix(image.Data, "data:image/png;base64,") {
+						t.Errorf("expected Data[%d].Data to be a PNG data URI, got: %.32s", i, image.Data)
+					}
+				}
+			},
+		},
+		{
+			name:           "Merged Image Test",
+			fields:         map[string]string{"merge": "true"},
+			pageCount:      3,
+			expectedStatus: http.StatusOK,
+			validateResp: func(t *testing.T, resp *apiV2.ConvertResponse) {
+				if len(resp.Data) != 1 {
+					t.Fatalf("expected exactly one merged image, got %d", len(resp.Data))
+				}
+
+				if resp.Data[0].Page != 0 {
+					t.Errorf("expected merged image page to be 0, got %d", resp.Data[0].Page)
+				}
+			},
+		},
+		{
+			name:              "Encrypted PDF Without Password Test",
+			isEncrypted:       true,
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodePasswordRequired,
+		},
+		{
+			name:              "Unsupported Format Test",
+			fields:            map[string]string{"format": "gif"},
+			expectedStatus:    http.StatusBadRequest,
+			expectedErrorCode: apiV1.ErrCodeUnsupportedFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileBuilder := &MockFileBuilder{
+				isEncrypted: tt.isEncrypted,
+			}
+
+			convertUsecase := usecase.NewConvertUsecase(
+				fileBuilder,
+				&MockImageConvertService{},
+				NewMockPdfDecryptService(true),
+				&MockPdfPageCountService{pageCount: tt.pageCount},
+				&MockPdfTextService{},
+				metrics.New(),
+				usecase.ConvertLimits{},
+			)
+			server := newTestServer(convertUsecase)
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			for key, value := range tt.fields {
+				_ = writer.WriteField(key, value)
+			}
+
+			part, err := writer.CreateFormFile("data", "test.pdf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = io.Copy(part, strings.NewReader("%PDF-1.5\n%%EOF\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = writer.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest("POST", "/v2/convert", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatus, recorder.Code)
+			}
+
+			if tt.expectedStatus != http.StatusOK {
+				var errorResp apiV1.Error
+				if err := json.Unmarshal(recorder.Body.Bytes(), &errorResp); err != nil {
+					t.Fatalf("failed to unmarshal error response: %v", err)
+				}
+
+				if errorResp.Code != tt.expectedErrorCode {
+					t.Errorf("expected error code %d, got %d", tt.expectedErrorCode, errorResp.Code)
+				}
+				return
+			}
+
+			var resp apiV2.ConvertResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(resp.Id) < 32 {
+				t.Errorf("expected UUID format for Id, got: %s", resp.Id)
+			}
+
+			if tt.validateResp != nil {
+				tt.validateResp(t, &resp)
+			}
+		})
+	}
+}
@@ -1,29 +1,240 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"github.com/elct9620/pdf64/internal/app"
 	"github.com/elct9620/pdf64/internal/builder"
+	"github.com/elct9620/pdf64/internal/config"
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/controller/health"
 	v1 "github.com/elct9620/pdf64/internal/controller/v1"
+	v2 "github.com/elct9620/pdf64/internal/controller/v2"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/repository"
 	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/tracing"
 	"github.com/elct9620/pdf64/internal/usecase"
 )
 
 func main() {
+	service.RunLimitedExecHelper()
+
+	if err := run(); err != nil {
+		slog.Error("Server stopped", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	cfg, err := config.Load(os.LookupEnv)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Every temporary file of this process lives under a single directory
+	// which is removed on exit, including files of cancelled conversions
+	tempDir, err := os.MkdirTemp(cfg.TempDir, "pdf64-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing.Exporter)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
+	if cfg.Webhook.Secret == "" {
+		slog.Warn("PDF64_WEBHOOK_SECRET is not set, webhook signatures cannot be trusted")
+	}
+
+	toolchain := service.Toolchain{
+		Magick:      cfg.Binaries.Magick,
+		Convert:     cfg.Binaries.Convert,
+		Identify:    cfg.Binaries.Identify,
+		Qpdf:        cfg.Binaries.Qpdf,
+		Ghostscript: cfg.Binaries.Ghostscript,
+		Pdftoppm:    cfg.Binaries.Pdftoppm,
+		Pdftotext:   cfg.Binaries.Pdftotext,
+		Mutool:      cfg.Binaries.Mutool,
+	}
+	resourceLimits := service.ResourceLimits{
+		Memory:          cfg.Limits.Conversion.MagickMemory,
+		Map:             cfg.Limits.Conversion.MagickMap,
+		Disk:            cfg.Limits.Conversion.MagickDisk,
+		Area:            cfg.Limits.Conversion.MagickArea,
+		Processes:       cfg.Limits.Conversion.Processes,
+		Timeout:         cfg.Limits.Conversion.Timeout,
+		ProcessMemory:   uint64(cfg.Limits.Conversion.ProcessMemory),
+		ProcessCPUTime:  cfg.Limits.Conversion.ProcessCPUTime,
+		ProcessFileSize: uint64(cfg.Limits.Conversion.ProcessFileSize),
+	}
+	defaultRenderer, err := usecase.ParseRenderer(cfg.Defaults.Renderer)
+	if err != nil {
+		return err
+	}
+	renderers := []usecase.Renderer{defaultRenderer}
+	for _, name := range cfg.Defaults.RendererFallbacks {
+		renderer, err := usecase.ParseRenderer(name)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(renderers, renderer) {
+			renderers = append(renderers, renderer)
+		}
+	}
+
 	// Initialize dependencies
-	fileBuilder := builder.NewFileBuilder()
-	imageConvertService := service.NewImageMagickConvertService()
-	pdfDecryptService := service.NewQpdfDecryptService()
-	convertUsecase := usecase.NewConvertUsecase(fileBuilder, imageConvertService, pdfDecryptService)
-	
+	convertMetrics := metrics.New()
+	fileBuilder := builder.NewFileBuilder(toolchain.Qpdf)
+	imageConvertService := service.NewLimitedImageConvertService(
+		service.NewFallbackImageConvertService(
+			service.NewRendererImageConvertService(defaultRenderer, map[usecase.Renderer]usecase.ImageConvertService{
+				usecase.RendererImageMagick: service.NewImageMagickConvertService(toolchain, tempDir, resourceLimits, convertMetrics),
+				usecase.RendererPdftoppm:    service.NewPdftoppmConvertService(toolchain, tempDir, resourceLimits, convertMetrics),
+				usecase.RendererMutool:      service.NewMutoolConvertService(toolchain, tempDir, resourceLimits, convertMetrics),
+				usecase.RendererGhostscript: service.NewGhostscriptConvertService(toolchain, tempDir, resourceLimits, convertMetrics),
+			}),
+			renderers,
+		),
+		service.LimitedImageConvertOptions{
+			Concurrency:  cfg.Limits.MaxConversions,
+			QueueSize:    cfg.Limits.ConversionQueueSize,
+			QueueTimeout: cfg.Limits.ConversionQueueTimeout,
+			RetryAfter:   cfg.Limits.RetryAfter,
+		},
+	)
+	pdfDecryptService := service.NewQpdfDecryptService(toolchain, convertMetrics)
+	pdfPageCountService := service.NewQpdfPageCountService(toolchain)
+	pdfTextService := service.NewLimitedPdfTextService(
+		service.NewPdftotextTextService(toolchain, resourceLimits, convertMetrics),
+		imageConvertService,
+	)
+	convertUsecase := usecase.NewConvertUsecase(fileBuilder, imageConvertService, pdfDecryptService, pdfPageCountService, pdfTextService, convertMetrics, usecase.ConvertLimits{
+		MaxPages:        cfg.Limits.MaxPages,
+		MaxOutputBytes:  cfg.Limits.MaxOutputBytes,
+		StreamBatchSize: cfg.Limits.Conversion.Processes,
+	})
+	pdfInfoService := service.NewLimitedPdfInfoService(
+		service.NewQpdfInfoService(toolchain, resourceLimits, service.DefaultMaxQpdfJSONBytes),
+		imageConvertService,
+	)
+	infoUsecase := usecase.NewInfoUsecase(fileBuilder, pdfDecryptService, pdfInfoService)
+	jobRepository := repository.NewMemoryJobRepository(cfg.Jobs.Retention)
+	jobQueue := service.NewWorkerPoolJobQueue(cfg.Jobs.Workers, cfg.Jobs.QueueSize, cfg.Limits.RetryAfter)
+	webhookAllowedNetworks, err := config.ParseNetworks(cfg.Webhook.AllowedNetworks)
+	if err != nil {
+		return err
+	}
+	webhookDeniedNetworks, err := config.ParseNetworks(cfg.Webhook.DeniedNetworks)
+	if err != nil {
+		return err
+	}
+	webhookNotifier := service.NewHttpWebhookNotifier(service.NewGuardedHttpClient(service.GuardedHttpClientOptions{
+		Timeout:         cfg.Webhook.Timeout,
+		AllowedNetworks: webhookAllowedNetworks,
+		DeniedNetworks:  webhookDeniedNetworks,
+	}), cfg.Webhook.Secret)
+	webhookUsecase := usecase.NewWebhookUsecase(jobRepository, webhookNotifier, controller.NewWebhookPresenter(), usecase.WebhookRetryPolicy{
+		MaxAttempts:    cfg.Webhook.MaxAttempts,
+		InitialBackoff: cfg.Webhook.InitialBackoff,
+		MaxBackoff:     cfg.Webhook.MaxBackoff,
+	})
+	jobUsecase := usecase.NewJobUsecase(convertUsecase, webhookUsecase, jobRepository, jobQueue)
+	allowedNetworks, err := config.ParseNetworks(cfg.Fetch.AllowedNetworks)
+	if err != nil {
+		return err
+	}
+	deniedNetworks, err := config.ParseNetworks(cfg.Fetch.DeniedNetworks)
+	if err != nil {
+		return err
+	}
+	documentFetcher := service.NewHttpDocumentFetcher(service.HttpDocumentFetcherOptions{
+		MaxBytes:        cfg.Fetch.MaxBytes,
+		Timeout:         cfg.Fetch.Timeout,
+		MaxRedirects:    cfg.Fetch.MaxRedirects,
+		AllowedHosts:    cfg.Fetch.AllowedHosts,
+		DeniedHosts:     cfg.Fetch.DeniedHosts,
+		AllowedNetworks: allowedNetworks,
+		DeniedNetworks:  deniedNetworks,
+	})
+	readinessChecks := []usecase.DependencyCheck{
+		toolchain.ImageMagickCheck(cfg.Readiness.CacheTTL),
+		toolchain.GhostscriptCheck(cfg.Readiness.CacheTTL),
+		toolchain.QpdfCheck(cfg.Readiness.CacheTTL),
+		toolchain.PdftotextCheck(cfg.Readiness.CacheTTL),
+		service.NewTempDirCheck(tempDir),
+		service.NewQueueCheck("job_queue", jobQueue),
+		service.NewQueueCheck("conversion_queue", imageConvertService),
+	}
+	// Any renderer can be selected per request, not only the fallback chain
+	for _, renderer := range usecase.SupportedRenderers {
+		switch renderer {
+		case usecase.RendererPdftoppm:
+			readinessChecks = append(readinessChecks, toolchain.PdftoppmCheck(cfg.Readiness.CacheTTL))
+		case usecase.RendererMutool:
+			readinessChecks = append(readinessChecks, toolchain.MutoolCheck(cfg.Readiness.CacheTTL))
+		}
+	}
+	readinessUsecase := usecase.NewReadinessUsecase(readinessChecks...)
+	convertMetrics.RegisterQueue("job_queue", jobQueue)
+	convertMetrics.RegisterQueue("conversion_queue", imageConvertService)
+
 	// Initialize controllers
-	apiV1 := v1.NewService(convertUsecase)
-	
+	inputBuilder := controller.NewInputBuilder(documentFetcher, controller.ConvertDefaults{
+		Density: cfg.Defaults.Density,
+		Quality: cfg.Defaults.Quality,
+		Format:  cfg.Defaults.Format,
+	}, tempDir)
+	apiV1 := v1.NewService(convertUsecase, jobUsecase, infoUsecase, inputBuilder)
+	apiV2 := v2.NewService(convertUsecase, inputBuilder)
+	apiHealth := health.NewService(readinessUsecase)
+
 	// Initialize server
-	server := app.NewServer(apiV1)
+	server := app.NewServer(cfg, apiV1, apiV2, apiHealth, convertMetrics)
+
+	listener, err := net.Listen("tcp", cfg.Server.Addr)
+	if err != nil {
+		return err
+	}
+
+	queueStopped := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
 
-	if err := http.ListenAndServe(":8080", server); err != nil {
-		panic(err)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+
+		queueStopped <- jobQueue.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Server started", "addr", listener.Addr().String())
+	serveErr := app.Serve(ctx, listener, server, cfg.Server.ShutdownTimeout)
+	stop()
+
+	if err := <-queueStopped; err != nil {
+		slog.Warn("Cancelled unfinished jobs after shutdown timeout", "error", err)
 	}
+
+	return serveErr
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/app"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	tests := []struct {
+		name               string
+		requestDuration    time.Duration
+		shutdownTimeout    time.Duration
+		expectedStatus     int
+		isRequestCancelled bool
+	}{
+		{
+			name:            "Drain In-flight Request Test",
+			requestDuration: 50 * time.Millisecond,
+			shutdownTimeout: 5 * time.Second,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:               "Cancel Request After Timeout Test",
+			requestDuration:    time.Minute,
+			shutdownTimeout:    50 * time.Millisecond,
+			expectedStatus:     http.StatusServiceUnavailable,
+			isRequestCancelled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			cancelled := make(chan bool, 1)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.requestDuration):
+					cancelled <- false
+					w.WriteHeader(http.StatusOK)
+				case <-r.Context().Done():
+					cancelled <- true
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, stop := context.WithCancel(context.Background())
+			defer stop()
+
+			served := make(chan error, 1)
+			go func() {
+				served <- app.Serve(ctx, listener, handler, tt.shutdownTimeout)
+			}()
+
+			url := "http://" + listener.Addr().String()
+			responded := make(chan *http.Response, 1)
+			go func() {
+				resp, err := http.Get(url)
+				if err != nil {
+					t.Errorf("in-flight request failed: %v", err)
+					responded <- nil
+					return
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				responded <- resp
+			}()
+
+			<-started
+			stop()
+
+			select {
+			case err := <-served:
+				if err != nil {
+					t.Fatalf("expected clean shutdown, got %v", err)
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("server did not shut down")
+			}
+
+			if resp := <-responded; resp != nil && resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			if isCancelled := <-cancelled; isCancelled != tt.isRequestCancelled {
+				t.Errorf("expected request cancelled: %v, got %v", tt.isRequestCancelled, isCancelled)
+			}
+
+			if _, err := http.Get(url); err == nil {
+				t.Error("expected new connections to be refused")
+			}
+		})
+	}
+}
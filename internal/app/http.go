@@ -1,8 +1,15 @@
 package app
 
 import (
+	"github.com/elct9620/pdf64/internal/config"
+	ctrlHealth "github.com/elct9620/pdf64/internal/controller/health"
 	ctrlV1 "github.com/elct9620/pdf64/internal/controller/v1"
+	ctrlV2 "github.com/elct9620/pdf64/internal/controller/v2"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/tracing"
+	"github.com/elct9620/pdf64/pkg/apis/health"
 	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	v2 "github.com/elct9620/pdf64/pkg/apis/v2"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/httplog/v2"
@@ -13,22 +20,41 @@ type Server struct {
 }
 
 func NewServer(
+	cfg *config.Config,
 	ctrlV1 *ctrlV1.Service,
+	ctrlV2 *ctrlV2.Service,
+	ctrlHealth *ctrlHealth.Service,
+	metrics *metrics.Metrics,
 ) *Server {
 	logger := httplog.NewLogger("pdf64", httplog.Options{
 		JSON:    true,
 		Concise: true,
 		QuietDownRoutes: []string{
 			"/livez",
+			"/readyz",
+			"/metrics",
 		},
 	})
 
 	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
 	r.Use(httplog.RequestLogger(logger))
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Heartbeat("/livez"))
+	r.Method("GET", "/metrics", metrics.Handler())
 
-	v1.Register(r, ctrlV1)
+	r.Group(func(r chi.Router) {
+		r.Use(metrics.Middleware)
+
+		options := v1.Options{
+			MaxFormMemory: cfg.Limits.MaxFormMemory,
+			MaxUploadSize: cfg.Limits.MaxUploadSize,
+		}
+
+		health.Register(r, ctrlHealth)
+		v1.Register(r, ctrlV1, options)
+		v2.Register(r, ctrlV2, options)
+	})
 
 	return &Server{
 		Router: r,
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// cancelGracePeriod is how long cancelled requests are given to return
+const cancelGracePeriod = 5 * time.Second
+
+// Serve handles connections from the listener until ctx is done. The server
+// then stops accepting connections and waits up to shutdownTimeout for the
+// in-flight requests, the requests still running afterwards are cancelled
+// through their context.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler, shutdownTimeout time.Duration) error {
+	baseCtx, cancelRequests := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelRequests()
+
+	server := &http.Server{
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server", "timeout", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		slog.Warn("Cancelling in-flight requests after shutdown timeout")
+		cancelRequests()
+
+		graceCtx, cancel := context.WithTimeout(context.Background(), cancelGracePeriod)
+		defer cancel()
+
+		if err := server.Shutdown(graceCtx); err != nil {
+			return server.Close()
+		}
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
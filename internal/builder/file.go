@@ -1,40 +1,65 @@
 package builder
 
 import (
+	"context"
 	"os/exec"
 
 	"github.com/elct9620/pdf64/internal/entity"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var tracer = otel.Tracer("github.com/elct9620/pdf64/internal/builder")
+
 // FileBuilder implements the usecase.FileBuilder interface
-type FileBuilder struct{}
+type FileBuilder struct {
+	qpdf string
+}
 
-// NewFileBuilder creates a new FileBuilder instance
-func NewFileBuilder() *FileBuilder {
-	return &FileBuilder{}
+// NewFileBuilder creates a new FileBuilder instance detecting encryption with
+// the qpdf binary at the given path
+func NewFileBuilder(qpdf string) *FileBuilder {
+	return &FileBuilder{
+		qpdf: qpdf,
+	}
 }
 
 // BuildFromPath creates a File entity from a file path
-func (b *FileBuilder) BuildFromPath(path string) (*entity.File, error) {
+func (b *FileBuilder) BuildFromPath(ctx context.Context, path string) (*entity.File, error) {
+	ctx, span := tracer.Start(ctx, "FileBuilder.BuildFromPath")
+	defer span.End()
+
 	id, err := uuid.NewV7()
 	if err != nil {
 		return nil, err
 	}
 
+	documentType, err := sniffDocumentType(path)
+	if err != nil {
+		return nil, err
+	}
+
 	file := entity.NewFile(id.String(), path)
+	file.SetType(documentType)
 
 	// Check if the file is encrypted using qpdf
-	if b.isEncrypted(path) {
+	if b.isEncrypted(ctx, path) {
 		file.Encrypt()
 	}
 
+	span.SetAttributes(
+		attribute.String("pdf64.file.id", file.Id()),
+		attribute.String("pdf64.file.type", string(file.Type())),
+		attribute.Bool("pdf64.file.encrypted", file.IsEncrypted()),
+	)
+
 	return file, nil
 }
 
 // isEncrypted checks if a PDF file is encrypted using qpdf
-func (b *FileBuilder) isEncrypted(path string) bool {
-	cmd := exec.Command("qpdf", "--is-encrypted", path)
+func (b *FileBuilder) isEncrypted(ctx context.Context, path string) bool {
+	cmd := exec.CommandContext(ctx, b.qpdf, "--is-encrypted", path)
 	err := cmd.Run()
 
 	// Return code 0 means the file is encrypted
@@ -2,12 +2,17 @@ package builder_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/elct9620/pdf64/internal/builder"
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
 )
 
 func TestFileBuilder_BuildFromPath(t *testing.T) {
@@ -40,12 +45,12 @@ func TestFileBuilder_BuildFromPath(t *testing.T) {
 
 	// Create an encrypted PDF file using qpdf with 256-bit encryption
 	encryptedPath := filepath.Join(tmpDir, "encrypted.pdf")
-	
+
 	var stderr bytes.Buffer
 	cmd := exec.Command("qpdf", "--encrypt", "password", "password", "256", "--", fixturesPdfPath, encryptedPath)
 	cmd.Stderr = &stderr
 	err = cmd.Run()
-	
+
 	if err != nil {
 		t.Fatalf("failed to create encrypted PDF: %v, stderr: %s", err, stderr.String())
 	}
@@ -54,16 +59,15 @@ func TestFileBuilder_BuildFromPath(t *testing.T) {
 	tests := []struct {
 		name              string
 		path              string
+		isError           bool
 		expectedId        bool
 		expectedPath      string
 		expectedEncrypted bool
 	}{
 		{
-			name:              "Basic File",
-			path:              "/path/to/file.pdf",
-			expectedId:        true,
-			expectedPath:      "/path/to/file.pdf",
-			expectedEncrypted: false,
+			name:    "Missing File",
+			path:    "/path/to/file.pdf",
+			isError: true,
 		},
 		{
 			name:              "Unencrypted PDF",
@@ -83,8 +87,14 @@ func TestFileBuilder_BuildFromPath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fileBuilder := builder.NewFileBuilder()
-			file, err := fileBuilder.BuildFromPath(tt.path)
+			fileBuilder := builder.NewFileBuilder("qpdf")
+			file, err := fileBuilder.BuildFromPath(context.Background(), tt.path)
+			if tt.isError {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
 
 			if err != nil {
 				t.Fatalf("expected no error, got %v", err)
@@ -102,9 +112,107 @@ func TestFileBuilder_BuildFromPath(t *testing.T) {
 				t.Errorf("expected path to be %q, got %q", tt.expectedPath, file.Path())
 			}
 
+			if file.Type() != entity.DocumentTypePDF {
+				t.Errorf("expected type to be %q, got %q", entity.DocumentTypePDF, file.Type())
+			}
+
 			if file.IsEncrypted() != tt.expectedEncrypted {
 				t.Errorf("expected IsEncrypted() to be %v, got %v", tt.expectedEncrypted, file.IsEncrypted())
 			}
 		})
 	}
 }
+
+func TestFileBuilder_SniffDocumentType(t *testing.T) {
+	pngHeader := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+	tests := []struct {
+		name         string
+		content      string
+		expectedErr  error
+		expectedType entity.DocumentType
+	}{
+		{
+			name:         "PDF Test",
+			content:      "%PDF-1.7\n1 0 obj\n<<>>\nendobj\n%%EOF\n",
+			expectedType: entity.DocumentTypePDF,
+		},
+		{
+			name:         "Leading Whitespace Test",
+			content:      "\r\n%PDF-1.4\n%%EOF\n",
+			expectedType: entity.DocumentTypePDF,
+		},
+		{
+			name:        "Empty Test",
+			content:     "",
+			expectedErr: usecase.ErrEmptyDocument,
+		},
+		{
+			name:        "Whitespace Only Test",
+			content:     " \n\n",
+			expectedErr: usecase.ErrEmptyDocument,
+		},
+		{
+			name:        "PNG Test",
+			content:     pngHeader,
+			expectedErr: usecase.ErrUnsupportedDocument,
+		},
+		{
+			name:        "PNG Polyglot Test",
+			content:     pngHeader + "%PDF-1.5\n%%EOF\n",
+			expectedErr: usecase.ErrUnsupportedDocument,
+		},
+		{
+			name:        "ZIP Polyglot Test",
+			content:     "%PDF-1.5\n%%EOF\nPK\x03\x04" + strings.Repeat("\x00", 26) + "PK\x05\x06" + strings.Repeat("\x00", 18),
+			expectedErr: usecase.ErrUnsupportedDocument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "upload.pdf")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := builder.NewFileBuilder("qpdf").BuildFromPath(context.Background(), path)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if file.Type() != tt.expectedType {
+				t.Errorf("expected type to be %q, got %q", tt.expectedType, file.Type())
+			}
+		})
+	}
+}
+
+func TestFileBuilder_QpdfPath(t *testing.T) {
+	// A qpdf which succeeds reports every document as encrypted
+	qpdf := filepath.Join(t.TempDir(), "custom-qpdf")
+	if err := os.WriteFile(qpdf, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "upload.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.5\n%%EOF\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := builder.NewFileBuilder(qpdf).BuildFromPath(context.Background(), path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !file.IsEncrypted() {
+		t.Error("expected the configured qpdf to detect the encryption")
+	}
+}
@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// sniffLength is the size of the header and trailer inspected, readers
+// accept a PDF header within the first 1024 bytes
+const sniffLength = 1024
+
+var (
+	pdfSignature = []byte("%PDF-")
+	// zipEndSignature marks the end of central directory of a ZIP archive
+	zipEndSignature = []byte("PK\x05\x06")
+)
+
+// sniffDocumentType detects the type from the header of the file and
+// rejects empty files and polyglots which are valid as another format too
+func sniffDocumentType(path string) (entity.DocumentType, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return entity.DocumentTypeUnknown, err
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return entity.DocumentTypeUnknown, err
+	}
+	header = header[:n]
+
+	if len(bytes.TrimSpace(header)) == 0 {
+		return entity.DocumentTypeUnknown, usecase.ErrEmptyDocument
+	}
+
+	content := bytes.TrimLeft(header, " \t\r\n")
+	if !bytes.HasPrefix(content, pdfSignature) {
+		detected := http.DetectContentType(header)
+		if bytes.Contains(header, pdfSignature) {
+			return entity.DocumentTypeUnknown, fmt.Errorf("%w: %s with an embedded PDF header", usecase.ErrUnsupportedDocument, detected)
+		}
+
+		return entity.DocumentTypeUnknown, fmt.Errorf("%w: detected %s", usecase.ErrUnsupportedDocument, detected)
+	}
+
+	trailer, err := readTrailer(file)
+	if err != nil {
+		return entity.DocumentTypeUnknown, err
+	}
+
+	if bytes.Contains(trailer, zipEndSignature) {
+		return entity.DocumentTypeUnknown, fmt.Errorf("%w: PDF with an appended ZIP archive", usecase.ErrUnsupportedDocument)
+	}
+
+	return entity.DocumentTypePDF, nil
+}
+
+// readTrailer returns the last sniffLength bytes of the file
+func readTrailer(file *os.File) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := max(info.Size()-sniffLength, 0)
+	trailer := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(trailer, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return trailer, nil
+}
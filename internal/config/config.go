@@ -0,0 +1,455 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/tracing"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable pointing to the optional YAML file
+const FileEnv = "PDF64_CONFIG"
+
+var (
+	densityPattern     = regexp.MustCompile(`^\d+(\.\d+)?(x\d+(\.\d+)?)?$`)
+	magickLimitPattern = regexp.MustCompile(`^\d+(\.\d+)?[A-Za-z]*$`)
+	hostLabelPattern   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// Config is the server configuration, the defaults are overridden by the
+// optional YAML file and then by environment variables
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Defaults  Defaults  `yaml:"defaults"`
+	Limits    Limits    `yaml:"limits"`
+	TempDir   string    `yaml:"temp_dir"`
+	Binaries  Binaries  `yaml:"binaries"`
+	Jobs      Jobs      `yaml:"jobs"`
+	Webhook   Webhook   `yaml:"webhook"`
+	Fetch     Fetch     `yaml:"fetch"`
+	Readiness Readiness `yaml:"readiness"`
+	Tracing   Tracing   `yaml:"tracing"`
+}
+
+type Server struct {
+	Addr            string        `yaml:"addr"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// Defaults are applied to conversion parameters which are not provided,
+// RendererFallbacks are tried in order when the default renderer fails
+type Defaults struct {
+	Density           string   `yaml:"density"`
+	Quality           int      `yaml:"quality"`
+	Format            string   `yaml:"format"`
+	Renderer          string   `yaml:"renderer"`
+	RendererFallbacks []string `yaml:"renderer_fallbacks"`
+}
+
+// Limits bound the resources used by requests, conversions beyond
+// MaxConversions wait in a queue of ConversionQueueSize for at most
+// ConversionQueueTimeout. A zero MaxPages or MaxOutputBytes is unlimited.
+type Limits struct {
+	MaxFormMemory          int64         `yaml:"max_form_memory"`
+	MaxUploadSize          int64         `yaml:"max_upload_size"`
+	MaxPages               int           `yaml:"max_pages"`
+	MaxOutputBytes         int64         `yaml:"max_output_bytes"`
+	MaxConversions         int           `yaml:"max_conversions"`
+	ConversionQueueSize    int           `yaml:"conversion_queue_size"`
+	ConversionQueueTimeout time.Duration `yaml:"conversion_queue_timeout"`
+	RetryAfter             time.Duration `yaml:"retry_after"`
+	Conversion             Conversion    `yaml:"conversion"`
+}
+
+// Conversion bounds the resources of a single ImageMagick invocation, the
+// ImageMagick limits use its units and empty or zero values are unlimited.
+// Processes bounds the ImageMagick processes of every conversion together.
+type Conversion struct {
+	Processes       int           `yaml:"processes"`
+	Timeout         time.Duration `yaml:"timeout"`
+	MagickMemory    string        `yaml:"magick_memory"`
+	MagickMap       string        `yaml:"magick_map"`
+	MagickDisk      string        `yaml:"magick_disk"`
+	MagickArea      string        `yaml:"magick_area"`
+	ProcessMemory   int64         `yaml:"process_memory"`
+	ProcessCPUTime  time.Duration `yaml:"process_cpu_time"`
+	ProcessFileSize int64         `yaml:"process_file_size"`
+}
+
+// Binaries are the paths or names of the external tools resolved from PATH
+type Binaries struct {
+	Magick      string `yaml:"magick"`
+	Convert     string `yaml:"convert"`
+	Identify    string `yaml:"identify"`
+	Qpdf        string `yaml:"qpdf"`
+	Ghostscript string `yaml:"ghostscript"`
+	Pdftoppm    string `yaml:"pdftoppm"`
+	Pdftotext   string `yaml:"pdftotext"`
+	Mutool      string `yaml:"mutool"`
+}
+
+type Jobs struct {
+	Workers   int           `yaml:"workers"`
+	QueueSize int           `yaml:"queue_size"`
+	Retention time.Duration `yaml:"retention"`
+}
+
+// Webhook configures the job callbacks, AllowedNetworks and DeniedNetworks
+// are CIDR prefixes and empty DeniedNetworks use the private ranges
+type Webhook struct {
+	Secret          string        `yaml:"secret"`
+	Timeout         time.Duration `yaml:"timeout"`
+	MaxAttempts     int           `yaml:"max_attempts"`
+	InitialBackoff  time.Duration `yaml:"initial_backoff"`
+	MaxBackoff      time.Duration `yaml:"max_backoff"`
+	AllowedNetworks []string      `yaml:"allowed_networks"`
+	DeniedNetworks  []string      `yaml:"denied_networks"`
+}
+
+// Fetch restricts remote documents, AllowedNetworks and DeniedNetworks are
+// CIDR prefixes and empty DeniedNetworks use the private ranges
+type Fetch struct {
+	MaxBytes        int64         `yaml:"max_bytes"`
+	Timeout         time.Duration `yaml:"timeout"`
+	MaxRedirects    int           `yaml:"max_redirects"`
+	AllowedHosts    []string      `yaml:"allowed_hosts"`
+	DeniedHosts     []string      `yaml:"denied_hosts"`
+	AllowedNetworks []string      `yaml:"allowed_networks"`
+	DeniedNetworks  []string      `yaml:"denied_networks"`
+}
+
+type Readiness struct {
+	// CacheTTL is how long the version of a binary is trusted before the
+	// binary is run again
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+type Tracing struct {
+	// Exporter is either none or otlp
+	Exporter string `yaml:"exporter"`
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:            ":8080",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Defaults: Defaults{
+			Density:  "150",
+			Quality:  90,
+			Format:   "jpeg",
+			Renderer: string(usecase.RendererImageMagick),
+		},
+		Limits: Limits{
+			MaxFormMemory:          32 << 20,
+			MaxUploadSize:          100 << 20,
+			MaxPages:               1000,
+			MaxOutputBytes:         512 << 20,
+			MaxConversions:         runtime.NumCPU(),
+			ConversionQueueSize:    2 * runtime.NumCPU(),
+			ConversionQueueTimeout: 30 * time.Second,
+			RetryAfter:             5 * time.Second,
+			Conversion: Conversion{
+				Processes:    runtime.NumCPU(),
+				Timeout:      2 * time.Minute,
+				MagickMemory: "512MiB",
+				MagickMap:    "1GiB",
+				MagickDisk:   "2GiB",
+				MagickArea:   "128MP",
+			},
+		},
+		TempDir: os.TempDir(),
+		Binaries: Binaries{
+			Magick:      "magick",
+			Convert:     "convert",
+			Identify:    "identify",
+			Qpdf:        "qpdf",
+			Ghostscript: "gs",
+			Pdftoppm:    "pdftoppm",
+			Pdftotext:   "pdftotext",
+			Mutool:      "mutool",
+		},
+		Jobs: Jobs{
+			Workers:   runtime.NumCPU(),
+			QueueSize: 100,
+			Retention: time.Hour,
+		},
+		Webhook: Webhook{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     30 * time.Second,
+		},
+		Fetch: Fetch{
+			MaxBytes:     100 << 20,
+			Timeout:      30 * time.Second,
+			MaxRedirects: 3,
+		},
+		Readiness: Readiness{
+			CacheTTL: time.Minute,
+		},
+		Tracing: Tracing{
+			Exporter: tracing.ExporterNone,
+		},
+	}
+}
+
+// Load reads the file named by PDF64_CONFIG when set, applies the environment
+// variables and validates the result
+func Load(lookupEnv func(string) (string, bool)) (*Config, error) {
+	config := Default()
+
+	if path, ok := lookupEnv(FileEnv); ok && path != "" {
+		if err := config.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.readEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Config) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(content, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) readEnv(lookupEnv func(string) (string, bool)) error {
+	env := &envReader{lookupEnv: lookupEnv}
+
+	if port, ok := lookupEnv("PORT"); ok && port != "" {
+		c.Server.Addr = ":" + port
+	}
+	env.string("PDF64_ADDR", &c.Server.Addr)
+	env.duration("PDF64_SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+
+	env.string("PDF64_DEFAULT_DENSITY", &c.Defaults.Density)
+	env.int("PDF64_DEFAULT_QUALITY", &c.Defaults.Quality)
+	env.string("PDF64_DEFAULT_FORMAT", &c.Defaults.Format)
+	env.string("PDF64_DEFAULT_RENDERER", &c.Defaults.Renderer)
+	env.list("PDF64_RENDERER_FALLBACKS", &c.Defaults.RendererFallbacks)
+
+	env.int64("PDF64_MAX_FORM_MEMORY", &c.Limits.MaxFormMemory)
+	env.int64("PDF64_MAX_UPLOAD_SIZE", &c.Limits.MaxUploadSize)
+	env.int("PDF64_MAX_PAGES", &c.Limits.MaxPages)
+	env.int64("PDF64_MAX_OUTPUT_BYTES", &c.Limits.MaxOutputBytes)
+	env.int("PDF64_MAX_CONVERSIONS", &c.Limits.MaxConversions)
+	env.int("PDF64_CONVERSION_QUEUE_SIZE", &c.Limits.ConversionQueueSize)
+	env.duration("PDF64_CONVERSION_QUEUE_TIMEOUT", &c.Limits.ConversionQueueTimeout)
+	env.duration("PDF64_RETRY_AFTER", &c.Limits.RetryAfter)
+	env.int("PDF64_CONVERSION_PROCESSES", &c.Limits.Conversion.Processes)
+	env.duration("PDF64_CONVERSION_TIMEOUT", &c.Limits.Conversion.Timeout)
+	env.string("PDF64_MAGICK_MEMORY_LIMIT", &c.Limits.Conversion.MagickMemory)
+	env.string("PDF64_MAGICK_MAP_LIMIT", &c.Limits.Conversion.MagickMap)
+	env.string("PDF64_MAGICK_DISK_LIMIT", &c.Limits.Conversion.MagickDisk)
+	env.string("PDF64_MAGICK_AREA_LIMIT", &c.Limits.Conversion.MagickArea)
+	env.int64("PDF64_PROCESS_MEMORY_LIMIT", &c.Limits.Conversion.ProcessMemory)
+	env.duration("PDF64_PROCESS_CPU_LIMIT", &c.Limits.Conversion.ProcessCPUTime)
+	env.int64("PDF64_PROCESS_FILE_SIZE_LIMIT", &c.Limits.Conversion.ProcessFileSize)
+
+	env.string("PDF64_TEMP_DIR", &c.TempDir)
+
+	env.string("PDF64_MAGICK_PATH", &c.Binaries.Magick)
+	env.string("PDF64_CONVERT_PATH", &c.Binaries.Convert)
+	env.string("PDF64_IDENTIFY_PATH", &c.Binaries.Identify)
+	env.string("PDF64_QPDF_PATH", &c.Binaries.Qpdf)
+	env.string("PDF64_GS_PATH", &c.Binaries.Ghostscript)
+	env.string("PDF64_PDFTOPPM_PATH", &c.Binaries.Pdftoppm)
+	env.string("PDF64_PDFTOTEXT_PATH", &c.Binaries.Pdftotext)
+	env.string("PDF64_MUTOOL_PATH", &c.Binaries.Mutool)
+
+	env.int("PDF64_JOB_WORKERS", &c.Jobs.Workers)
+	env.int("PDF64_JOB_QUEUE_SIZE", &c.Jobs.QueueSize)
+	env.duration("PDF64_JOB_RETENTION", &c.Jobs.Retention)
+
+	env.string("PDF64_WEBHOOK_SECRET", &c.Webhook.Secret)
+	env.duration("PDF64_WEBHOOK_TIMEOUT", &c.Webhook.Timeout)
+	env.int("PDF64_WEBHOOK_MAX_ATTEMPTS", &c.Webhook.MaxAttempts)
+	env.duration("PDF64_WEBHOOK_INITIAL_BACKOFF", &c.Webhook.InitialBackoff)
+	env.duration("PDF64_WEBHOOK_MAX_BACKOFF", &c.Webhook.MaxBackoff)
+	env.list("PDF64_WEBHOOK_ALLOWED_NETWORKS", &c.Webhook.AllowedNetworks)
+	env.list("PDF64_WEBHOOK_DENIED_NETWORKS", &c.Webhook.DeniedNetworks)
+
+	env.int64("PDF64_FETCH_MAX_BYTES", &c.Fetch.MaxBytes)
+	env.duration("PDF64_FETCH_TIMEOUT", &c.Fetch.Timeout)
+	env.int("PDF64_FETCH_MAX_REDIRECTS", &c.Fetch.MaxRedirects)
+	env.list("PDF64_FETCH_ALLOWED_HOSTS", &c.Fetch.AllowedHosts)
+	env.list("PDF64_FETCH_DENIED_HOSTS", &c.Fetch.DeniedHosts)
+	env.list("PDF64_FETCH_ALLOWED_NETWORKS", &c.Fetch.AllowedNetworks)
+	env.list("PDF64_FETCH_DENIED_NETWORKS", &c.Fetch.DeniedNetworks)
+
+	env.duration("PDF64_READINESS_CACHE_TTL", &c.Readiness.CacheTTL)
+
+	env.string("OTEL_TRACES_EXPORTER", &c.Tracing.Exporter)
+
+	return errors.Join(env.errs...)
+}
+
+// Validate reports every invalid setting at once
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Server.Addr == "" {
+		errs = append(errs, errors.New("server address must not be empty"))
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("shutdown timeout must be positive"))
+	}
+
+	if !densityPattern.MatchString(c.Defaults.Density) {
+		errs = append(errs, fmt.Errorf("default density %q must be a number or NxM", c.Defaults.Density))
+	}
+
+	if c.Defaults.Quality < 1 || c.Defaults.Quality > 100 {
+		errs = append(errs, fmt.Errorf("default quality %d must be between 1 and 100", c.Defaults.Quality))
+	}
+
+	if _, err := usecase.ParseImageFormat(c.Defaults.Format); err != nil {
+		errs = append(errs, fmt.Errorf("default format %q is not supported", c.Defaults.Format))
+	}
+
+	if renderer, err := usecase.ParseRenderer(c.Defaults.Renderer); err != nil || renderer == usecase.RendererDefault {
+		errs = append(errs, fmt.Errorf("default renderer %q is not supported", c.Defaults.Renderer))
+	}
+
+	for _, name := range c.Defaults.RendererFallbacks {
+		if renderer, err := usecase.ParseRenderer(name); err != nil || renderer == usecase.RendererDefault {
+			errs = append(errs, fmt.Errorf("fallback renderer %q is not supported", name))
+		}
+	}
+
+	if c.Limits.MaxFormMemory <= 0 || c.Limits.MaxUploadSize <= 0 {
+		errs = append(errs, errors.New("max form memory and max upload size must be positive"))
+	}
+
+	if c.Limits.MaxPages < 0 || c.Limits.MaxOutputBytes < 0 {
+		errs = append(errs, errors.New("max pages and max output bytes must not be negative"))
+	}
+
+	if c.Limits.MaxConversions <= 0 || c.Limits.ConversionQueueSize < 0 || c.Limits.ConversionQueueTimeout <= 0 || c.Limits.RetryAfter <= 0 {
+		errs = append(errs, errors.New("max conversions, conversion queue timeout and retry after must be positive and conversion queue size not negative"))
+	}
+
+	if info, err := os.Stat(c.TempDir); err != nil || !info.IsDir() {
+		errs = append(errs, fmt.Errorf("temp directory %q must be an existing directory", c.TempDir))
+	}
+
+	conversion := c.Limits.Conversion
+	if conversion.Timeout < 0 || conversion.ProcessMemory < 0 || conversion.ProcessCPUTime < 0 || conversion.ProcessFileSize < 0 {
+		errs = append(errs, errors.New("conversion timeout and process limits must not be negative"))
+	}
+
+	if conversion.Processes <= 0 {
+		errs = append(errs, errors.New("conversion processes must be positive"))
+	}
+
+	for _, limit := range []string{conversion.MagickMemory, conversion.MagickMap, conversion.MagickDisk, conversion.MagickArea} {
+		if limit != "" && !magickLimitPattern.MatchString(limit) {
+			errs = append(errs, fmt.Errorf("ImageMagick limit %q must be a number with an optional unit", limit))
+		}
+	}
+
+	if c.Binaries.Magick == "" || c.Binaries.Convert == "" || c.Binaries.Identify == "" || c.Binaries.Qpdf == "" || c.Binaries.Ghostscript == "" || c.Binaries.Pdftoppm == "" || c.Binaries.Pdftotext == "" || c.Binaries.Mutool == "" {
+		errs = append(errs, errors.New("binary paths must not be empty"))
+	}
+
+	if c.Jobs.Workers <= 0 || c.Jobs.QueueSize <= 0 || c.Jobs.Retention <= 0 {
+		errs = append(errs, errors.New("job workers, queue size and retention must be positive"))
+	}
+
+	if c.Webhook.Timeout <= 0 || c.Webhook.MaxAttempts <= 0 || c.Webhook.InitialBackoff <= 0 || c.Webhook.MaxBackoff < c.Webhook.InitialBackoff {
+		errs = append(errs, errors.New("webhook timeout, attempts and backoff must be positive and max backoff not less than initial backoff"))
+	}
+
+	if c.Fetch.MaxBytes <= 0 || c.Fetch.Timeout <= 0 || c.Fetch.MaxRedirects < 0 {
+		errs = append(errs, errors.New("fetch max bytes and timeout must be positive and max redirects not negative"))
+	}
+
+	for _, pattern := range slices.Concat(c.Fetch.AllowedHosts, c.Fetch.DeniedHosts) {
+		if !isHostPattern(pattern) {
+			errs = append(errs, fmt.Errorf("host %q must be a host name, optionally prefixed with *.", pattern))
+		}
+	}
+
+	for _, networks := range [][]string{c.Webhook.AllowedNetworks, c.Webhook.DeniedNetworks, c.Fetch.AllowedNetworks, c.Fetch.DeniedNetworks} {
+		if _, err := ParseNetworks(networks); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.Readiness.CacheTTL < 0 {
+		errs = append(errs, errors.New("readiness cache ttl must not be negative"))
+	}
+
+	if c.Tracing.Exporter != tracing.ExporterNone && c.Tracing.Exporter != tracing.ExporterOTLP {
+		errs = append(errs, fmt.Errorf("tracing exporter %q must be none or otlp", c.Tracing.Exporter))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// isHostPattern accepts a host name or IP address, host names may start
+// with "*." to match their subdomains
+func isHostPattern(pattern string) bool {
+	if _, err := netip.ParseAddr(pattern); err == nil {
+		return true
+	}
+
+	for _, label := range strings.Split(strings.TrimPrefix(pattern, "*."), ".") {
+		if !hostLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseNetworks parses CIDR prefixes such as "10.0.0.0/8", an empty list is
+// returned as nil
+func ParseNetworks(values []string) ([]netip.Prefix, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("network %q must be a CIDR prefix", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,260 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/config"
+)
+
+func TestLoad(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "pdf64.yaml")
+	err := os.WriteFile(configFile, []byte(`
+server:
+  addr: ":9000"
+defaults:
+  density: "300"
+  quality: 80
+jobs:
+  retention: 30m
+fetch:
+  allowed_hosts:
+    - example.com
+  denied_networks:
+    - 10.0.0.0/8
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		env           map[string]string
+		expectedError string
+		validate      func(t *testing.T, cfg *config.Config)
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			validate: func(t *testing.T, cfg *config.Config) {
+				if cfg.Server.Addr != ":8080" {
+					t.Errorf("expected addr :8080, got %s", cfg.Server.Addr)
+				}
+
+				if cfg.Defaults.Density != "150" || cfg.Defaults.Quality != 90 || cfg.Defaults.Format != "jpeg" {
+					t.Errorf("unexpected defaults %+v", cfg.Defaults)
+				}
+
+				if cfg.Limits.MaxFormMemory != 32<<20 {
+					t.Errorf("expected max form memory %d, got %d", 32<<20, cfg.Limits.MaxFormMemory)
+				}
+			},
+		},
+		{
+			name: "port from environment",
+			env:  map[string]string{"PORT": "3000"},
+			validate: func(t *testing.T, cfg *config.Config) {
+				if cfg.Server.Addr != ":3000" {
+					t.Errorf("expected addr :3000, got %s", cfg.Server.Addr)
+				}
+			},
+		},
+		{
+			name: "address overrides port",
+			env:  map[string]string{"PORT": "3000", "PDF64_ADDR": "127.0.0.1:4000"},
+			validate: func(t *testing.T, cfg *config.Config) {
+				if cfg.Server.Addr != "127.0.0.1:4000" {
+					t.Errorf("expected addr 127.0.0.1:4000, got %s", cfg.Server.Addr)
+				}
+			},
+		},
+		{
+			name: "environment overrides",
+			env: map[string]string{
+				"PDF64_DEFAULT_FORMAT":         "png",
+				"PDF64_MAX_FORM_MEMORY":        "1024",
+				"PDF64_QPDF_PATH":              "/opt/qpdf/bin/qpdf",
+				"PDF64_JOB_WORKERS":            "2",
+				"PDF64_WEBHOOK_MAX_BACKOFF":    "1m",
+				"PDF64_FETCH_DENIED_HOSTS":     "internal.example.com, *.corp.example.com",
+				"PDF64_RENDERER_FALLBACKS":     "mutool, pdftoppm",
+				"PDF64_FETCH_ALLOWED_NETWORKS": "10.1.0.0/16, fd00::/8",
+			},
+			validate: func(t *testing.T, cfg *config.Config) {
+				if cfg.Defaults.Format != "png" {
+					t.Errorf("expected format png, got %s", cfg.Defaults.Format)
+				}
+
+				if cfg.Limits.MaxFormMemory != 1024 {
+					t.Errorf("expected max form memory 1024, got %d", cfg.Limits.MaxFormMemory)
+				}
+
+				if cfg.Binaries.Qpdf != "/opt/qpdf/bin/qpdf" {
+					t.Errorf("expected qpdf path /opt/qpdf/bin/qpdf, got %s", cfg.Binaries.Qpdf)
+				}
+
+				if cfg.Jobs.Workers != 2 {
+					t.Errorf("expected 2 workers, got %d", cfg.Jobs.Workers)
+				}
+
+				if cfg.Webhook.MaxBackoff != time.Minute {
+					t.Errorf("expected max backoff 1m, got %s", cfg.Webhook.MaxBackoff)
+				}
+
+				if !slices.Equal(cfg.Fetch.DeniedHosts, []string{"internal.example.com", "*.corp.example.com"}) {
+					t.Errorf("unexpected denied hosts %v", cfg.Fetch.DeniedHosts)
+				}
+
+				if !slices.Equal(cfg.Defaults.RendererFallbacks, []string{"mutool", "pdftoppm"}) {
+					t.Errorf("unexpected renderer fallbacks %v", cfg.Defaults.RendererFallbacks)
+				}
+
+				if !slices.Equal(cfg.Fetch.AllowedNetworks, []string{"10.1.0.0/16", "fd00::/8"}) {
+					t.Errorf("unexpected allowed networks %v", cfg.Fetch.AllowedNetworks)
+				}
+			},
+		},
+		{
+			name: "file with environment overrides",
+			env:  map[string]string{config.FileEnv: configFile, "PDF64_DEFAULT_QUALITY": "70"},
+			validate: func(t *testing.T, cfg *config.Config) {
+				if cfg.Server.Addr != ":9000" {
+					t.Errorf("expected addr :9000, got %s", cfg.Server.Addr)
+				}
+
+				if cfg.Defaults.Density != "300" || cfg.Defaults.Quality != 70 || cfg.Defaults.Format != "jpeg" {
+					t.Errorf("unexpected defaults %+v", cfg.Defaults)
+				}
+
+				if cfg.Jobs.Retention != 30*time.Minute {
+					t.Errorf("expected retention 30m, got %s", cfg.Jobs.Retention)
+				}
+
+				if !slices.Equal(cfg.Fetch.AllowedHosts, []string{"example.com"}) {
+					t.Errorf("unexpected allowed hosts %v", cfg.Fetch.AllowedHosts)
+				}
+
+				if !slices.Equal(cfg.Fetch.DeniedNetworks, []string{"10.0.0.0/8"}) {
+					t.Errorf("unexpected denied networks %v", cfg.Fetch.DeniedNetworks)
+				}
+			},
+		},
+		{
+			name:          "missing file",
+			env:           map[string]string{config.FileEnv: filepath.Join(t.TempDir(), "missing.yaml")},
+			expectedError: "failed to read config file",
+		},
+		{
+			name:          "malformed integer",
+			env:           map[string]string{"PDF64_DEFAULT_QUALITY": "high"},
+			expectedError: "PDF64_DEFAULT_QUALITY must be an integer",
+		},
+		{
+			name:          "malformed duration",
+			env:           map[string]string{"PDF64_JOB_RETENTION": "forever"},
+			expectedError: "PDF64_JOB_RETENTION must be a duration",
+		},
+		{
+			name:          "invalid quality",
+			env:           map[string]string{"PDF64_DEFAULT_QUALITY": "101"},
+			expectedError: "default quality 101 must be between 1 and 100",
+		},
+		{
+			name:          "invalid density",
+			env:           map[string]string{"PDF64_DEFAULT_DENSITY": "high"},
+			expectedError: `default density "high" must be a number or NxM`,
+		},
+		{
+			name:          "unsupported format",
+			env:           map[string]string{"PDF64_DEFAULT_FORMAT": "gif"},
+			expectedError: `default format "gif" is not supported`,
+		},
+		{
+			name:          "missing temp directory",
+			env:           map[string]string{"PDF64_TEMP_DIR": filepath.Join(t.TempDir(), "missing")},
+			expectedError: "must be an existing directory",
+		},
+		{
+			name:          "invalid backoff",
+			env:           map[string]string{"PDF64_WEBHOOK_INITIAL_BACKOFF": "1m", "PDF64_WEBHOOK_MAX_BACKOFF": "1s"},
+			expectedError: "max backoff not less than initial backoff",
+		},
+		{
+			name:          "non-positive max upload size",
+			env:           map[string]string{"PDF64_MAX_UPLOAD_SIZE": "0"},
+			expectedError: "max form memory and max upload size must be positive",
+		},
+		{
+			name:          "negative max pages",
+			env:           map[string]string{"PDF64_MAX_PAGES": "-1"},
+			expectedError: "max pages and max output bytes must not be negative",
+		},
+		{
+			name:          "invalid ImageMagick limit",
+			env:           map[string]string{"PDF64_MAGICK_MEMORY_LIMIT": "lots"},
+			expectedError: `ImageMagick limit "lots" must be a number with an optional unit`,
+		},
+		{
+			name:          "negative conversion timeout",
+			env:           map[string]string{"PDF64_CONVERSION_TIMEOUT": "-1s"},
+			expectedError: "conversion timeout and process limits must not be negative",
+		},
+		{
+			name:          "zero conversion processes",
+			env:           map[string]string{"PDF64_CONVERSION_PROCESSES": "0"},
+			expectedError: "conversion processes must be positive",
+		},
+		{
+			name:          "unsupported default renderer",
+			env:           map[string]string{"PDF64_DEFAULT_RENDERER": "pdfium"},
+			expectedError: `default renderer "pdfium" is not supported`,
+		},
+		{
+			name:          "unsupported fallback renderer",
+			env:           map[string]string{"PDF64_RENDERER_FALLBACKS": "mutool,pdfium"},
+			expectedError: `fallback renderer "pdfium" is not supported`,
+		},
+		{
+			name:          "invalid fetch host pattern",
+			env:           map[string]string{"PDF64_FETCH_ALLOWED_HOSTS": "example.com,*example.com"},
+			expectedError: `host "*example.com" must be a host name, optionally prefixed with *.`,
+		},
+		{
+			name:          "invalid fetch network",
+			env:           map[string]string{"PDF64_FETCH_DENIED_NETWORKS": "10.0.0.0/8,internal"},
+			expectedError: `network "internal" must be a CIDR prefix`,
+		},
+		{
+			name:          "unsupported tracing exporter",
+			env:           map[string]string{"OTEL_TRACES_EXPORTER": "jaeger"},
+			expectedError: `tracing exporter "jaeger" must be none or otlp`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupEnv := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+
+			cfg, err := config.Load(lookupEnv)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			tt.validate(t, cfg)
+		})
+	}
+}
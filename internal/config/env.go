@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReader overrides settings with environment variables and collects
+// parsing errors
+type envReader struct {
+	lookupEnv func(string) (string, bool)
+	errs      []error
+}
+
+func (r *envReader) lookup(name string) (string, bool) {
+	value, ok := r.lookupEnv(name)
+	if !ok || value == "" {
+		return "", false
+	}
+
+	return value, true
+}
+
+func (r *envReader) string(name string, target *string) {
+	if value, ok := r.lookup(name); ok {
+		*target = value
+	}
+}
+
+func (r *envReader) int(name string, target *int) {
+	value, ok := r.lookup(name)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s must be an integer: %q", name, value))
+		return
+	}
+
+	*target = number
+}
+
+func (r *envReader) int64(name string, target *int64) {
+	value, ok := r.lookup(name)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s must be an integer: %q", name, value))
+		return
+	}
+
+	*target = number
+}
+
+func (r *envReader) duration(name string, target *time.Duration) {
+	value, ok := r.lookup(name)
+	if !ok {
+		return
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("%s must be a duration: %q", name, value))
+		return
+	}
+
+	*target = duration
+}
+
+// list splits a comma separated value
+func (r *envReader) list(name string, target *[]string) {
+	value, ok := r.lookup(name)
+	if !ok {
+		return
+	}
+
+	*target = strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
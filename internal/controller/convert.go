@@ -0,0 +1,266 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// ConvertDefaults are applied to the parameters a request does not provide
+type ConvertDefaults struct {
+	Density string
+	Quality int
+	Format  string
+}
+
+// InputBuilder creates the usecase input from a request by saving the
+// uploaded or remote document into a temporary file under tempDir
+type InputBuilder struct {
+	fetcher  usecase.DocumentFetcher
+	defaults ConvertDefaults
+	tempDir  string
+}
+
+func NewInputBuilder(fetcher usecase.DocumentFetcher, defaults ConvertDefaults, tempDir string) *InputBuilder {
+	return &InputBuilder{
+		fetcher:  fetcher,
+		defaults: defaults,
+		tempDir:  tempDir,
+	}
+}
+
+// Build saves the document and applies the defaults, the caller is
+// responsible for removing the file at FilePath
+func (b *InputBuilder) Build(ctx context.Context, req *v1.ConvertRequest) (*usecase.ConvertInput, error) {
+	filePath, err := b.saveDocument(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &usecase.ConvertInput{
+		FilePath:    filePath,
+		Password:    req.Password,
+		Density:     b.defaults.Density,
+		Quality:     b.defaults.Quality,
+		Merge:       req.Merge,
+		Pages:       req.Pages,
+		Format:      b.defaults.Format,
+		Width:       req.Width,
+		Height:      req.Height,
+		MaxPixels:   req.MaxPixels,
+		Renderer:    req.Renderer,
+		IncludeText: req.IncludeText,
+	}
+
+	if req.Quality > 0 {
+		input.Quality = req.Quality
+	}
+
+	if req.Density != "" {
+		input.Density = req.Density
+	}
+
+	if req.Format != "" {
+		input.Format = req.Format
+	}
+
+	return input, nil
+}
+
+func (b *InputBuilder) saveDocument(ctx context.Context, req *v1.ConvertRequest) (string, error) {
+	if req.File != nil {
+		return b.save(req.File)
+	}
+
+	body, err := b.fetcher.Fetch(ctx, req.URL)
+	if err != nil {
+		return "", ConvertError(err)
+	}
+	defer body.Close()
+
+	filePath, err := b.save(body)
+	if err != nil {
+		return "", ConvertError(err)
+	}
+
+	return filePath, nil
+}
+
+func (b *InputBuilder) save(src io.Reader) (string, error) {
+	tmpFile, err := os.CreateTemp(b.tempDir, "pdf64-*.pdf")
+	if err != nil {
+		return "", err
+	}
+	defer tmpFile.Close()
+
+	if _, err := io.Copy(tmpFile, src); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", err
+	}
+
+	return tmpFile.Name(), nil
+}
+
+// ConvertError translates known usecase errors into API errors
+func ConvertError(err error) error {
+	switch {
+	case errors.Is(err, usecase.ErrPasswordRequired):
+		return v1.Error{
+			Code:    v1.ErrCodePasswordRequired,
+			Message: "Password is required for encrypted PDF",
+		}
+	case errors.Is(err, usecase.ErrInvalidPassword):
+		return v1.Error{
+			Code:    v1.ErrCodeInvalidPassword,
+			Message: "Password is incorrect for encrypted PDF",
+		}
+	case errors.Is(err, usecase.ErrUnsupportedDocument):
+		return v1.Error{
+			Code:    v1.ErrCodeUnsupportedDocument,
+			Message: "Document is not a PDF" + strings.TrimPrefix(err.Error(), usecase.ErrUnsupportedDocument.Error()),
+		}
+	case errors.Is(err, usecase.ErrEmptyDocument):
+		return v1.Error{
+			Code:    v1.ErrCodeEmptyDocument,
+			Message: "Document is empty",
+		}
+	case errors.Is(err, usecase.ErrCorruptDocument):
+		return v1.Error{
+			Code:    v1.ErrCodeCorruptDocument,
+			Message: "Document is corrupt and cannot be read",
+		}
+	case errors.Is(err, usecase.ErrTooManyPages):
+		return v1.Error{
+			Code:    v1.ErrCodeTooManyPages,
+			Message: "Document has too many pages" + strings.TrimPrefix(err.Error(), usecase.ErrTooManyPages.Error()),
+		}
+	case errors.Is(err, usecase.ErrInvalidPageRange):
+		return v1.Error{
+			Code:    v1.ErrCodeInvalidPageRange,
+			Message: "Invalid page range" + strings.TrimPrefix(err.Error(), usecase.ErrInvalidPageRange.Error()),
+		}
+	case errors.Is(err, usecase.ErrInvalidSize):
+		return v1.Error{
+			Code:    v1.ErrCodeBadRequest,
+			Message: "Width, height and max_pixels must not be negative",
+		}
+	case errors.Is(err, usecase.ErrUnsupportedFormat):
+		return v1.Error{
+			Code:    v1.ErrCodeUnsupportedFormat,
+			Message: "Unsupported image format, expected one of: " + supportedFormatNames(),
+		}
+	case errors.Is(err, usecase.ErrUnsupportedRenderer):
+		return v1.Error{
+			Code:    v1.ErrCodeUnsupportedRenderer,
+			Message: "Unsupported renderer, expected one of: " + supportedRendererNames(),
+		}
+	case errors.Is(err, usecase.ErrJobNotFound):
+		return v1.Error{
+			Code:    v1.ErrCodeJobNotFound,
+			Message: "Job not found",
+		}
+	case errors.Is(err, usecase.ErrJobNotFinished):
+		return v1.Error{
+			Code:    v1.ErrCodeJobNotFinished,
+			Message: "Job is not finished yet",
+		}
+	case errors.Is(err, usecase.ErrQueueFull):
+		return v1.Error{
+			Code:       v1.ErrCodeQueueFull,
+			Message:    "Job queue is full, retry later",
+			RetryAfter: retryAfterSeconds(err),
+		}
+	case errors.Is(err, usecase.ErrQueueClosed):
+		return v1.Error{
+			Code:    v1.ErrCodeQueueFull,
+			Message: "Server is shutting down, retry later",
+		}
+	case errors.Is(err, usecase.ErrFetchForbidden):
+		return v1.Error{
+			Code:    v1.ErrCodeFetchForbidden,
+			Message: "Document url is not allowed" + strings.TrimPrefix(err.Error(), usecase.ErrFetchForbidden.Error()),
+		}
+	case errors.Is(err, usecase.ErrFetchFailed):
+		return v1.Error{
+			Code:    v1.ErrCodeFetchFailed,
+			Message: "Failed to fetch document" + strings.TrimPrefix(err.Error(), usecase.ErrFetchFailed.Error()),
+		}
+	case errors.Is(err, usecase.ErrFileTooLarge):
+		return v1.Error{
+			Code:    v1.ErrCodeMaxFileSize,
+			Message: "Document is too large" + strings.TrimPrefix(err.Error(), usecase.ErrFileTooLarge.Error()),
+		}
+	case errors.Is(err, usecase.ErrOutputTooLarge):
+		return v1.Error{
+			Code:    v1.ErrCodeMaxFileSize,
+			Message: "Output is too large" + strings.TrimPrefix(err.Error(), usecase.ErrOutputTooLarge.Error()),
+		}
+	case errors.Is(err, usecase.ErrServerBusy):
+		return v1.Error{
+			Code:       v1.ErrCodeServerBusy,
+			Message:    "Server is busy, retry later",
+			RetryAfter: retryAfterSeconds(err),
+		}
+	case errors.Is(err, usecase.ErrResourceLimitExceeded):
+		return v1.Error{
+			Code:    v1.ErrCodeResourceLimitExceeded,
+			Message: "Conversion exceeded the resource limits" + strings.TrimPrefix(err.Error(), usecase.ErrResourceLimitExceeded.Error()),
+		}
+	case errors.Is(err, usecase.ErrConversionTimeout):
+		return v1.Error{
+			Code:    v1.ErrCodeConversionTimeout,
+			Message: "Conversion timed out" + strings.TrimPrefix(err.Error(), usecase.ErrConversionTimeout.Error()),
+		}
+	case errors.Is(err, usecase.ErrInvalidCallbackURL):
+		return v1.Error{
+			Code:    v1.ErrCodeInvalidCallbackURL,
+			Message: "Callback URL must be an absolute http or https URL",
+		}
+	}
+	return err
+}
+
+// FailureError describes the reason of a failed conversion as an API error
+func FailureError(err error) *v1.Error {
+	var apiErr v1.Error
+	if errors.As(ConvertError(err), &apiErr) {
+		return &apiErr
+	}
+
+	return &v1.Error{
+		Code:    v1.ErrCodeInternal,
+		Message: "Conversion failed",
+	}
+}
+
+// retryAfterSeconds rounds the delay of a retryable error up to whole seconds
+func retryAfterSeconds(err error) int {
+	var retryableErr *usecase.RetryableError
+	if !errors.As(err, &retryableErr) {
+		return 0
+	}
+
+	return int(math.Ceil(retryableErr.RetryAfter.Seconds()))
+}
+
+func supportedFormatNames() string {
+	names := make([]string, 0, len(usecase.SupportedImageFormats))
+	for _, format := range usecase.SupportedImageFormats {
+		names = append(names, string(format))
+	}
+	return strings.Join(names, ", ")
+}
+
+func supportedRendererNames() string {
+	names := make([]string, 0, len(usecase.SupportedRenderers))
+	for _, renderer := range usecase.SupportedRenderers {
+		names = append(names, string(renderer))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/elct9620/pdf64/pkg/apis/health"
+)
+
+var _ health.ServiceImpl = &Service{}
+
+type Service struct {
+	readinessUsecase *usecase.ReadinessUsecase
+}
+
+func NewService(readinessUsecase *usecase.ReadinessUsecase) *Service {
+	return &Service{
+		readinessUsecase: readinessUsecase,
+	}
+}
+
+func (s *Service) Ready(ctx context.Context) *health.ReadinessResponse {
+	report := s.readinessUsecase.Check(ctx)
+
+	resp := &health.ReadinessResponse{
+		Status:       health.StatusReady,
+		Dependencies: make([]health.DependencyStatus, 0, len(report.Dependencies)),
+	}
+
+	if !report.IsReady() {
+		resp.Status = health.StatusUnavailable
+	}
+
+	for _, dependency := range report.Dependencies {
+		status := health.DependencyStatus{
+			Name:   dependency.Name,
+			Status: health.StatusReady,
+			Detail: dependency.Detail,
+		}
+
+		if !dependency.IsReady() {
+			status.Status = health.StatusUnavailable
+			status.Error = dependency.Err.Error()
+		}
+
+		resp.Dependencies = append(resp.Dependencies, status)
+	}
+
+	return resp
+}
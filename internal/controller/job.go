@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// NewConvertResponse creates the v1 response from the conversion output
+func NewConvertResponse(out *usecase.ConvertOutput) *v1.ConvertResponse {
+	data := make([]string, 0, len(out.Images))
+	sizes := make([]v1.ImageSize, 0, len(out.Images))
+	var texts []string
+	for _, image := range out.Images {
+		data = append(data, image.DataURI())
+		sizes = append(sizes, v1.ImageSize{
+			Width:  image.Width,
+			Height: image.Height,
+		})
+
+		if image.Text != nil {
+			texts = append(texts, *image.Text)
+		}
+	}
+
+	return &v1.ConvertResponse{
+		Id:       out.FileId,
+		Data:     data,
+		Pages:    out.Pages,
+		Sizes:    sizes,
+		Renderer: string(out.Renderer),
+		Text:     texts,
+	}
+}
+
+// NewJobResponse describes the job with its failure reason and webhook deliveries
+func NewJobResponse(job *entity.Job) *v1.JobResponse {
+	resp := &v1.JobResponse{
+		Id:        job.Id(),
+		Status:    v1.JobStatus(job.Status()),
+		CreatedAt: job.CreatedAt(),
+		UpdatedAt: job.UpdatedAt(),
+	}
+
+	if job.Err() != nil {
+		resp.Error = FailureError(job.Err())
+	}
+
+	for _, delivery := range job.Deliveries() {
+		item := v1.WebhookDelivery{
+			Attempt:     delivery.Attempt,
+			StatusCode:  delivery.StatusCode,
+			DeliveredAt: delivery.DeliveredAt,
+		}
+
+		if delivery.Err != nil {
+			item.Error = delivery.Err.Error()
+		}
+
+		resp.Deliveries = append(resp.Deliveries, item)
+	}
+
+	return resp
+}
+
+// WebhookPresenter encodes the callback payload in the same shape as the v1 API
+type WebhookPresenter struct{}
+
+func NewWebhookPresenter() *WebhookPresenter {
+	return &WebhookPresenter{}
+}
+
+func (p *WebhookPresenter) Present(job *entity.Job, output *usecase.ConvertOutput) ([]byte, error) {
+	callback := v1.JobCallback{
+		JobResponse: *NewJobResponse(job),
+	}
+	callback.Deliveries = nil
+
+	if output != nil {
+		callback.Result = NewConvertResponse(output)
+	}
+
+	return json.Marshal(callback)
+}
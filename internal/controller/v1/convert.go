@@ -2,74 +2,60 @@ package v1
 
 import (
 	"context"
-	"io"
 	"os"
-	"path/filepath"
 
+	"github.com/elct9620/pdf64/internal/controller"
 	"github.com/elct9620/pdf64/internal/usecase"
 	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
 )
 
 func (s *Service) Convert(ctx context.Context, req *v1.ConvertRequest) (*v1.ConvertResponse, error) {
-	// Create temporary file
-	tmpDir, err := os.MkdirTemp("", "pdf64-")
+	out, err := s.convert(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	filePath := filepath.Join(tmpDir, "upload.pdf")
 
-	// Create temporary file
-	tmpFile, err := os.Create(filePath)
-	if err != nil {
-		return nil, err
-	}
-	defer tmpFile.Close()
+	return controller.NewConvertResponse(out), nil
+}
 
-	// Copy uploaded file content to temporary file
-	_, err = io.Copy(tmpFile, req.File)
+func (s *Service) ConvertRaw(ctx context.Context, req *v1.ConvertRequest) (*v1.ConvertRawResponse, error) {
+	out, err := s.convert(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ensure file is written and closed
-	tmpFile.Close()
+	images := make([]v1.RawImage, 0, len(out.Images))
+	for _, image := range out.Images {
+		images = append(images, v1.RawImage{
+			Page:     image.Page,
+			Width:    image.Width,
+			Height:   image.Height,
+			MimeType: image.MimeType,
+			Data:     image.Data,
+			Text:     image.Text,
+		})
+	}
 
-	// Delete temporary file when function exits
-	defer os.Remove(filePath)
+	return &v1.ConvertRawResponse{
+		Id:     out.FileId,
+		Images: images,
+	}, nil
+}
 
-	// Parse quality parameter
-	quality := 90 // Default quality
-	if req.Quality > 0 {
-		quality = req.Quality
+func (s *Service) convert(ctx context.Context, req *v1.ConvertRequest) (*usecase.ConvertOutput, error) {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	// Use density from request or default
-	density := "150" // Default density
-	if req.Density != "" {
-		density = req.Density
-	}
+	// Delete temporary file when function exits
+	defer os.Remove(input.FilePath)
 
 	// Execute conversion use case
-	out, err := s.convertUsecase.Execute(ctx, &usecase.ConvertInput{
-		FilePath: filePath,
-		Password: req.Password,
-		Density:  density,
-		Quality:  quality,
-		Merge:    req.Merge,
-	})
+	out, err := s.convertUsecase.Execute(ctx, input)
 	if err != nil {
-		// Handle specific errors
-		if err == usecase.ErrPasswordRequired {
-			return nil, v1.Error{
-				Code:    v1.ErrCodePasswordRequired,
-				Message: "Password is required for encrypted PDF",
-			}
-		}
-		return nil, err
+		return nil, controller.ConvertError(err)
 	}
 
-	return &v1.ConvertResponse{
-		Id:   out.FileId,
-		Data: out.EncodedImages,
-	}, nil
+	return out, nil
 }
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"context"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func (s *Service) Info(ctx context.Context, req *v1.ConvertRequest) (*v1.InfoResponse, error) {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete temporary file when function exits
+	defer os.Remove(input.FilePath)
+
+	out, err := s.infoUsecase.Execute(ctx, &usecase.InfoInput{
+		FilePath: input.FilePath,
+		Password: input.Password,
+	})
+	if err != nil {
+		return nil, controller.ConvertError(err)
+	}
+
+	info := out.Info
+	pages := make([]v1.PageInfo, 0, len(info.Pages))
+	for _, page := range info.Pages {
+		pages = append(pages, v1.PageInfo{
+			Page:     page.Number,
+			Width:    page.Width,
+			Height:   page.Height,
+			Rotation: page.Rotation,
+			HasText:  page.HasText,
+		})
+	}
+
+	return &v1.InfoResponse{
+		Id:          out.FileId,
+		PdfVersion:  info.PdfVersion,
+		PageCount:   len(info.Pages),
+		IsEncrypted: out.IsEncrypted,
+		Metadata: v1.DocumentMetadata{
+			Title:    info.Metadata.Title,
+			Author:   info.Metadata.Author,
+			Subject:  info.Metadata.Subject,
+			Keywords: info.Metadata.Keywords,
+			Creator:  info.Metadata.Creator,
+			Producer: info.Metadata.Producer,
+		},
+		Pages: pages,
+	}, nil
+}
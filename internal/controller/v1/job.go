@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"context"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/controller"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func (s *Service) SubmitJob(ctx context.Context, req *v1.ConvertRequest) (*v1.JobResponse, error) {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobUsecase.Submit(ctx, input, req.CallbackURL)
+	if err != nil {
+		os.Remove(input.FilePath)
+		return nil, controller.ConvertError(err)
+	}
+
+	return controller.NewJobResponse(job), nil
+}
+
+func (s *Service) GetJob(ctx context.Context, id string) (*v1.JobResponse, error) {
+	job, err := s.jobUsecase.Find(ctx, id)
+	if err != nil {
+		return nil, controller.ConvertError(err)
+	}
+
+	return controller.NewJobResponse(job), nil
+}
+
+func (s *Service) GetJobResult(ctx context.Context, id string) (*v1.ConvertResponse, error) {
+	out, err := s.jobUsecase.Result(ctx, id)
+	if err != nil {
+		return nil, *controller.FailureError(err)
+	}
+
+	return controller.NewConvertResponse(out), nil
+}
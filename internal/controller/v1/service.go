@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"github.com/elct9620/pdf64/internal/controller"
 	"github.com/elct9620/pdf64/internal/usecase"
 	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
 )
@@ -9,10 +10,16 @@ var _ v1.ServiceImpl = &Service{}
 
 type Service struct {
 	convertUsecase *usecase.ConvertUsecase
+	jobUsecase     *usecase.JobUsecase
+	infoUsecase    *usecase.InfoUsecase
+	inputBuilder   *controller.InputBuilder
 }
 
-func NewService(convertUsecase *usecase.ConvertUsecase) *Service {
+func NewService(convertUsecase *usecase.ConvertUsecase, jobUsecase *usecase.JobUsecase, infoUsecase *usecase.InfoUsecase, inputBuilder *controller.InputBuilder) *Service {
 	return &Service{
 		convertUsecase: convertUsecase,
+		jobUsecase:     jobUsecase,
+		infoUsecase:    infoUsecase,
+		inputBuilder:   inputBuilder,
 	}
 }
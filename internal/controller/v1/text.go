@@ -0,0 +1,37 @@
+package v1
+
+import (
+	"context"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/controller"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+func (s *Service) Text(ctx context.Context, req *v1.ConvertRequest) (*v1.TextResponse, error) {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete temporary file when function exits
+	defer os.Remove(input.FilePath)
+
+	out, err := s.convertUsecase.ExtractText(ctx, input)
+	if err != nil {
+		return nil, controller.ConvertError(err)
+	}
+
+	pages := make([]v1.PageText, 0, len(out.Pages))
+	for _, page := range out.Pages {
+		pages = append(pages, v1.PageText{
+			Page: page.Page,
+			Text: page.Text,
+		})
+	}
+
+	return &v1.TextResponse{
+		Id:    out.FileId,
+		Pages: pages,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"context"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	v2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+func (s *Service) Convert(ctx context.Context, req *v1.ConvertRequest) (*v2.ConvertResponse, error) {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delete temporary file when function exits
+	defer os.Remove(input.FilePath)
+
+	out, err := s.convertUsecase.Execute(ctx, input)
+	if err != nil {
+		return nil, controller.ConvertError(err)
+	}
+
+	images := make([]v2.Image, 0, len(out.Images))
+	for i := range out.Images {
+		images = append(images, newImage(&out.Images[i]))
+	}
+
+	return &v2.ConvertResponse{
+		Id:   out.FileId,
+		Data: images,
+	}, nil
+}
+
+func newImage(image *usecase.Image) v2.Image {
+	return v2.Image{
+		Page:     image.Page,
+		Width:    image.Width,
+		Height:   image.Height,
+		MimeType: image.MimeType,
+		Bytes:    len(image.Data),
+		Data:     image.DataURI(),
+		Renderer: string(image.Renderer),
+		Text:     image.Text,
+	}
+}
@@ -0,0 +1,33 @@
+package v2
+
+import (
+	"context"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	v2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+func (s *Service) ConvertStream(ctx context.Context, req *v1.ConvertRequest, emit func(event *v2.ImageEvent) error) error {
+	input, err := s.inputBuilder.Build(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	// Delete temporary file when function exits
+	defer os.Remove(input.FilePath)
+
+	err = s.convertUsecase.Stream(ctx, input, func(fileId string, image *usecase.Image) error {
+		return emit(&v2.ImageEvent{
+			Id:    fileId,
+			Image: newImage(image),
+		})
+	})
+	if err != nil {
+		return controller.ConvertError(err)
+	}
+
+	return nil
+}
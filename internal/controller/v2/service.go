@@ -0,0 +1,21 @@
+package v2
+
+import (
+	"github.com/elct9620/pdf64/internal/controller"
+	"github.com/elct9620/pdf64/internal/usecase"
+	v2 "github.com/elct9620/pdf64/pkg/apis/v2"
+)
+
+var _ v2.ServiceImpl = &Service{}
+
+type Service struct {
+	convertUsecase *usecase.ConvertUsecase
+	inputBuilder   *controller.InputBuilder
+}
+
+func NewService(convertUsecase *usecase.ConvertUsecase, inputBuilder *controller.InputBuilder) *Service {
+	return &Service{
+		convertUsecase: convertUsecase,
+		inputBuilder:   inputBuilder,
+	}
+}
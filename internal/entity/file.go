@@ -1,9 +1,18 @@
 package entity
 
+// DocumentType is the media type detected from the content of a file
+type DocumentType string
+
+const (
+	DocumentTypeUnknown DocumentType = ""
+	DocumentTypePDF     DocumentType = "application/pdf"
+)
+
 type File struct {
-	id          string
-	path        string
-	isEncrypted bool
+	id           string
+	path         string
+	documentType DocumentType
+	isEncrypted  bool
 }
 
 func NewFile(id, path string) *File {
@@ -22,6 +31,16 @@ func (f *File) Path() string {
 	return f.path
 }
 
+// Type returns the detected document type, it is unknown until the content
+// is sniffed
+func (f *File) Type() DocumentType {
+	return f.documentType
+}
+
+func (f *File) SetType(documentType DocumentType) {
+	f.documentType = documentType
+}
+
 func (f *File) IsEncrypted() bool {
 	return f.isEncrypted
 }
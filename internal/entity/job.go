@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"slices"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// WebhookDelivery records an attempt to notify the callback URL of a job
+type WebhookDelivery struct {
+	Attempt     int
+	StatusCode  int
+	Err         error
+	DeliveredAt time.Time
+}
+
+func (d WebhookDelivery) IsSucceeded() bool {
+	return d.Err == nil
+}
+
+// Job tracks an asynchronous conversion of the File with the same id
+type Job struct {
+	id          string
+	status      JobStatus
+	err         error
+	callbackURL string
+	deliveries  []WebhookDelivery
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+func NewJob(id, callbackURL string) *Job {
+	now := time.Now()
+
+	return &Job{
+		id:          id,
+		status:      JobStatusQueued,
+		callbackURL: callbackURL,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+}
+
+func (j *Job) Id() string {
+	return j.id
+}
+
+func (j *Job) Status() JobStatus {
+	return j.status
+}
+
+// Err returns the failure reason of a failed job
+func (j *Job) Err() error {
+	return j.err
+}
+
+func (j *Job) CallbackURL() string {
+	return j.callbackURL
+}
+
+func (j *Job) Deliveries() []WebhookDelivery {
+	return slices.Clone(j.deliveries)
+}
+
+// RecordDelivery appends the attempt without sharing storage with copies of the job
+func (j *Job) RecordDelivery(delivery WebhookDelivery) {
+	j.deliveries = append(slices.Clip(j.deliveries), delivery)
+}
+
+func (j *Job) CreatedAt() time.Time {
+	return j.createdAt
+}
+
+func (j *Job) UpdatedAt() time.Time {
+	return j.updatedAt
+}
+
+func (j *Job) IsFinished() bool {
+	return j.status == JobStatusSucceeded || j.status == JobStatusFailed
+}
+
+func (j *Job) Start() {
+	j.transit(JobStatusRunning)
+}
+
+func (j *Job) Succeed() {
+	j.transit(JobStatusSucceeded)
+}
+
+func (j *Job) Fail(err error) {
+	j.err = err
+	j.transit(JobStatusFailed)
+}
+
+func (j *Job) transit(status JobStatus) {
+	j.status = status
+	j.updatedAt = time.Now()
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pdf64"
+
+var _ usecase.ConvertMetrics = &Metrics{}
+
+// Metrics collects the HTTP and conversion metrics into its own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	requestDurations  *prometheus.HistogramVec
+	phaseDurations    *prometheus.HistogramVec
+	pagesRendered     prometheus.Counter
+	outputBytes       prometheus.Counter
+	inFlight          prometheus.Gauge
+	subprocessFailure *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, status and API error code.",
+		}, []string{"method", "route", "status", "error_code"}),
+		requestDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route and status.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"method", "route", "status"}),
+		phaseDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "conversion_phase_duration_seconds",
+			Help:      "Duration of the decrypt, rasterize and encode phases of conversions.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"phase"}),
+		pagesRendered: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "pages_rendered_total",
+			Help:      "Pages rendered into images.",
+		}),
+		outputBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "output_bytes_total",
+			Help:      "Bytes of rendered images before encoding into the response.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "conversions_in_flight",
+			Help:      "Conversions currently running.",
+		}),
+		subprocessFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subprocess_failures_total",
+			Help:      "External commands which exited with an error.",
+		}, []string{"command"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDurations,
+		m.phaseDurations,
+		m.pagesRendered,
+		m.outputBytes,
+		m.inFlight,
+		m.subprocessFailure,
+	)
+
+	return m
+}
+
+// Queue exposes the occupancy of a bounded queue
+type Queue interface {
+	Len() int
+	Cap() int
+}
+
+// RegisterQueue reports the length and capacity of the queue on every scrape
+func (m *Metrics) RegisterQueue(name string, queue Queue) {
+	labels := prometheus.Labels{"queue": name}
+
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "queue_length",
+			Help:        "Entries admitted to the queue.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(queue.Len())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Name:        "queue_capacity",
+			Help:        "Entries the queue admits before rejecting.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(queue.Cap())
+		}),
+	)
+}
+
+// Handler exposes the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts the requests by the route pattern, it must be used by
+// routed groups to keep the cardinality of the route label bounded
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startedAt := time.Now()
+		errorCode := "none"
+		ctx := v1.WithErrorCodeRecorder(r.Context(), func(code v1.ErrorCode) {
+			errorCode = strconv.Itoa(int(code))
+		})
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route := chi.RouteContext(ctx).RoutePattern()
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		statusLabel := strconv.Itoa(status)
+
+		m.requests.WithLabelValues(r.Method, route, statusLabel, errorCode).Inc()
+		m.requestDurations.WithLabelValues(r.Method, route, statusLabel).Observe(time.Since(startedAt).Seconds())
+	})
+}
+
+func (m *Metrics) ConversionStarted() func() {
+	m.inFlight.Inc()
+	return m.inFlight.Dec
+}
+
+func (m *Metrics) ObservePhase(phase usecase.ConvertPhase, duration time.Duration) {
+	m.phaseDurations.WithLabelValues(string(phase)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveOutput(pages int, images []usecase.Image) {
+	m.pagesRendered.Add(float64(pages))
+	for _, image := range images {
+		m.outputBytes.Add(float64(len(image.Data)))
+	}
+}
+
+func (m *Metrics) ObserveSubprocessFailure(command string) {
+	m.subprocessFailure.WithLabelValues(command).Inc()
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.JobRepository = &MemoryJobRepository{}
+
+type memoryJobRecord struct {
+	job    entity.Job
+	output *usecase.ConvertOutput
+}
+
+// MemoryJobRepository implements the usecase.JobRepository interface in memory,
+// finished jobs and their results are discarded after the retention period
+type MemoryJobRepository struct {
+	mutex     sync.RWMutex
+	records   map[string]*memoryJobRecord
+	retention time.Duration
+}
+
+// NewMemoryJobRepository creates a new MemoryJobRepository instance
+func NewMemoryJobRepository(retention time.Duration) *MemoryJobRepository {
+	return &MemoryJobRepository{
+		records:   make(map[string]*memoryJobRecord),
+		retention: retention,
+	}
+}
+
+// Save stores a copy of the job
+func (r *MemoryJobRepository) Save(ctx context.Context, job *entity.Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.prune()
+
+	record, ok := r.records[job.Id()]
+	if !ok {
+		record = &memoryJobRecord{}
+		r.records[job.Id()] = record
+	}
+	record.job = *job
+
+	return nil
+}
+
+// Find returns a copy of the job
+func (r *MemoryJobRepository) Find(ctx context.Context, id string) (*entity.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	record, ok := r.records[id]
+	if !ok || r.isExpired(record) {
+		return nil, usecase.ErrJobNotFound
+	}
+
+	job := record.job
+	return &job, nil
+}
+
+// Delete removes the job and its result, a missing job is not an error
+func (r *MemoryJobRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.records, id)
+
+	return nil
+}
+
+func (r *MemoryJobRepository) SaveResult(ctx context.Context, id string, output *usecase.ConvertOutput) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return usecase.ErrJobNotFound
+	}
+	record.output = output
+
+	return nil
+}
+
+func (r *MemoryJobRepository) FindResult(ctx context.Context, id string) (*usecase.ConvertOutput, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	record, ok := r.records[id]
+	if !ok || r.isExpired(record) || record.output == nil {
+		return nil, usecase.ErrJobNotFound
+	}
+
+	return record.output, nil
+}
+
+func (r *MemoryJobRepository) prune() {
+	for id, record := range r.records {
+		if r.isExpired(record) {
+			delete(r.records, id)
+		}
+	}
+}
+
+func (r *MemoryJobRepository) isExpired(record *memoryJobRecord) bool {
+	return record.job.IsFinished() && time.Since(record.job.UpdatedAt()) > r.retention
+}
@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/repository"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestMemoryJobRepository(t *testing.T) {
+	tests := []struct {
+		name           string
+		retention      time.Duration
+		finish         bool
+		delete         bool
+		expectedStatus entity.JobStatus
+		expectedError  error
+	}{
+		{
+			name:           "Queued Job",
+			retention:      time.Hour,
+			expectedStatus: entity.JobStatusQueued,
+		},
+		{
+			name:           "Succeeded Job",
+			retention:      time.Hour,
+			finish:         true,
+			expectedStatus: entity.JobStatusSucceeded,
+		},
+		{
+			name:          "Expired Job",
+			retention:     -time.Second,
+			finish:        true,
+			expectedError: usecase.ErrJobNotFound,
+		},
+		{
+			name:          "Deleted Job",
+			retention:     time.Hour,
+			delete:        true,
+			expectedError: usecase.ErrJobNotFound,
+		},
+		{
+			name:           "Unfinished Job Never Expires",
+			retention:      -time.Second,
+			expectedStatus: entity.JobStatusQueued,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewMemoryJobRepository(tt.retention)
+
+			job := entity.NewJob("test-id", "")
+			if err := repo.Save(ctx, job); err != nil {
+				t.Fatalf("failed to save job: %v", err)
+			}
+
+			if tt.finish {
+				if err := repo.SaveResult(ctx, job.Id(), &usecase.ConvertOutput{FileId: job.Id()}); err != nil {
+					t.Fatalf("failed to save result: %v", err)
+				}
+
+				job.Succeed()
+				if err := repo.Save(ctx, job); err != nil {
+					t.Fatalf("failed to save job: %v", err)
+				}
+			}
+
+			if tt.delete {
+				if err := repo.Delete(ctx, job.Id()); err != nil {
+					t.Fatalf("failed to delete job: %v", err)
+				}
+			}
+
+			found, err := repo.Find(ctx, job.Id())
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if found.Status() != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s", tt.expectedStatus, found.Status())
+			}
+
+			found.Fail(errors.New("mutated"))
+			stored, _ := repo.Find(ctx, job.Id())
+			if stored.Status() != tt.expectedStatus {
+				t.Error("expected repository to store a copy of the job")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.DependencyCheck = &CommandCheck{}
+
+// commandCheckTimeout limits how long a version command may run
+const commandCheckTimeout = 5 * time.Second
+
+// CommandCheck verifies an external binary is resolvable and runnable by
+// asking for its version. The version is cached for ttl per resolved path so
+// frequent probes don't spawn a process every time.
+type CommandCheck struct {
+	name       string
+	candidates []string
+	args       []string
+	ttl        time.Duration
+
+	mutex     sync.Mutex
+	path      string
+	version   string
+	checkedAt time.Time
+}
+
+// NewCommandCheck runs the first candidate found in PATH with args, the
+// first line of its output is reported as the version
+func NewCommandCheck(name string, ttl time.Duration, candidates []string, args ...string) *CommandCheck {
+	return &CommandCheck{
+		name:       name,
+		candidates: candidates,
+		args:       args,
+		ttl:        ttl,
+	}
+}
+
+// ImageMagickCheck prefers the magick command like the convert service does
+func (t Toolchain) ImageMagickCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("imagemagick", ttl, []string{t.Magick, t.Convert}, "-version")
+}
+
+func (t Toolchain) GhostscriptCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("ghostscript", ttl, []string{t.Ghostscript}, "--version")
+}
+
+func (t Toolchain) QpdfCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("qpdf", ttl, []string{t.Qpdf}, "--version")
+}
+
+func (t Toolchain) PdftoppmCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("pdftoppm", ttl, []string{t.Pdftoppm}, "-v")
+}
+
+func (t Toolchain) PdftotextCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("pdftotext", ttl, []string{t.Pdftotext}, "-v")
+}
+
+func (t Toolchain) MutoolCheck(ttl time.Duration) *CommandCheck {
+	return NewCommandCheck("mutool", ttl, []string{t.Mutool}, "-v")
+}
+
+func (c *CommandCheck) Name() string {
+	return c.name
+}
+
+func (c *CommandCheck) Check(ctx context.Context) (string, error) {
+	path, err := c.lookPath()
+	if err != nil {
+		return "", err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.path == path && time.Since(c.checkedAt) < c.ttl {
+		return c.version, nil
+	}
+
+	version, err := c.run(ctx, path)
+	if err != nil {
+		c.path = ""
+		return "", err
+	}
+
+	c.path = path
+	c.version = version
+	c.checkedAt = time.Now()
+
+	return version, nil
+}
+
+func (c *CommandCheck) lookPath() (string, error) {
+	var lastErr error
+	for _, candidate := range c.candidates {
+		path, err := exec.LookPath(candidate)
+		if err == nil {
+			return path, nil
+		}
+		lastErr = err
+	}
+
+	return "", fmt.Errorf("failed to find %s: %w", c.name, lastErr)
+}
+
+func (c *CommandCheck) run(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandCheckTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, c.args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", path, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Scan()
+
+	return strings.TrimSpace(scanner.Text()), nil
+}
@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/service"
+)
+
+func TestCommandCheck_CachesVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		ttl           time.Duration
+		expectedCalls int
+	}{
+		{
+			name:          "Cached Version Test",
+			ttl:           time.Minute,
+			expectedCalls: 1,
+		},
+		{
+			name:          "Expired Version Test",
+			ttl:           0,
+			expectedCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			calls := filepath.Join(dir, "calls")
+			binary := filepath.Join(dir, "qpdf")
+			script := "#!/bin/sh\necho call >> " + calls + "\necho 'qpdf version 11.9.0'\necho 'Copyright'\n"
+			if err := os.WriteFile(binary, []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			check := service.NewCommandCheck("qpdf", tt.ttl, []string{"missing-qpdf", binary}, "--version")
+			for range 3 {
+				version, err := check.Check(context.Background())
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if version != "qpdf version 11.9.0" {
+					t.Errorf("expected first output line as version, got %q", version)
+				}
+			}
+
+			content, err := os.ReadFile(calls)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count := strings.Count(string(content), "call"); count != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, count)
+			}
+		})
+	}
+}
+
+func TestCommandCheck_FailingBinary(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "gs")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	check := service.NewCommandCheck("ghostscript", time.Minute, []string{binary}, "--version")
+	if _, err := check.Check(context.Background()); err == nil {
+		t.Error("expected an error for a failing binary")
+	}
+}
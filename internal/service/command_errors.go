@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// qpdfExitWarning is the exit code of qpdf when it succeeded with warnings,
+// e.g. after recovering a damaged cross-reference table
+const qpdfExitWarning = 3
+
+// commandErrorMessage maps a message a command reports on stderr to the
+// usecase error describing the document
+type commandErrorMessage struct {
+	message string
+	err     error
+}
+
+// qpdfErrorMessages are checked in order, the header check precedes the
+// damage reports qpdf prints while trying to recover a non-PDF file
+var qpdfErrorMessages = []commandErrorMessage{
+	{"invalid password", usecase.ErrInvalidPassword},
+	{"can't find PDF header", usecase.ErrUnsupportedDocument},
+	{"not a PDF file", usecase.ErrUnsupportedDocument},
+	{"unable to find trailer dictionary", usecase.ErrCorruptDocument},
+	{"can't find startxref", usecase.ErrCorruptDocument},
+	{"file is damaged", usecase.ErrCorruptDocument},
+}
+
+// imageMagickErrorMessages covers ImageMagick and the Ghostscript delegate
+var imageMagickErrorMessages = []commandErrorMessage{
+	{"no decode delegate for this image format", usecase.ErrUnsupportedDocument},
+	{"improper image header", usecase.ErrUnsupportedDocument},
+	{"list length exceeds limit", usecase.ErrTooManyPages},
+	{"Unrecoverable error", usecase.ErrCorruptDocument},
+	{"**** Error", usecase.ErrCorruptDocument},
+}
+
+// pdftoppmErrorMessages are reported by Poppler, including pdftotext
+var pdftoppmErrorMessages = []commandErrorMessage{
+	{"Incorrect password", usecase.ErrInvalidPassword},
+	{"May not be a PDF file", usecase.ErrUnsupportedDocument},
+	{"Couldn't find trailer dictionary", usecase.ErrCorruptDocument},
+	{"Couldn't read xref table", usecase.ErrCorruptDocument},
+	{"Syntax Error", usecase.ErrCorruptDocument},
+}
+
+// mutoolErrorMessages are reported by MuPDF
+var mutoolErrorMessages = []commandErrorMessage{
+	{"cannot authenticate password", usecase.ErrInvalidPassword},
+	{"cannot recognize version marker", usecase.ErrUnsupportedDocument},
+	{"cannot find startxref", usecase.ErrCorruptDocument},
+	{"no objects found", usecase.ErrCorruptDocument},
+}
+
+// ghostscriptErrorMessages are reported by Ghostscript
+var ghostscriptErrorMessages = []commandErrorMessage{
+	{"Password did not work", usecase.ErrInvalidPassword},
+	{"Unrecoverable error", usecase.ErrCorruptDocument},
+	{"**** Error", usecase.ErrCorruptDocument},
+}
+
+// conversionError classifies the failure of a rendering command, ctx is the
+// context of the request and limitCtx the one bounded by the timeout. It is
+// nil when the failure is not caused by the document or the limits.
+func conversionError(ctx, limitCtx context.Context, limits ResourceLimits, err error, stderr string, messages []commandErrorMessage) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if limits.isTimedOut(limitCtx, stderr) {
+		// Without a timeout the time limit comes from the ImageMagick policy
+		if limits.Timeout == 0 {
+			return usecase.ErrConversionTimeout
+		}
+
+		return fmt.Errorf("%w after %s", usecase.ErrConversionTimeout, limits.Timeout)
+	}
+
+	if limit := exceededLimit(err, stderr); limit != "" {
+		return fmt.Errorf("%w: %s", usecase.ErrResourceLimitExceeded, limit)
+	}
+
+	return documentError(stderr, messages)
+}
+
+// documentError returns the usecase error matching the stderr of a failed
+// command, it is nil when the failure is not caused by the document
+func documentError(stderr string, messages []commandErrorMessage) error {
+	for _, entry := range messages {
+		if strings.Contains(stderr, entry.message) {
+			return entry.err
+		}
+	}
+
+	return nil
+}
+
+// isQpdfWarning reports whether qpdf completed the operation with warnings only
+func isQpdfWarning(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == qpdfExitWarning
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestQpdfServices_DocumentErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        string
+		expectedErr   error
+		expectedCount int
+	}{
+		{
+			name:        "Invalid Password Test",
+			script:      "echo 'test.pdf: invalid password' >&2; exit 2",
+			expectedErr: usecase.ErrInvalidPassword,
+		},
+		{
+			name:        "Not A PDF Test",
+			script:      "echo 'WARNING: test.pdf: can'\"'\"'t find PDF header' >&2; echo 'test.pdf: unable to find trailer dictionary while recovering damaged file' >&2; exit 2",
+			expectedErr: usecase.ErrUnsupportedDocument,
+		},
+		{
+			name:        "Corrupt Document Test",
+			script:      "echo 'test.pdf: unable to find trailer dictionary while recovering damaged file' >&2; exit 2",
+			expectedErr: usecase.ErrCorruptDocument,
+		},
+		{
+			name:          "Recovered With Warnings Test",
+			script:        "echo 'WARNING: test.pdf: file is damaged' >&2; echo 3; exit 3",
+			expectedCount: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, _ := writeFakeCommand(t, tt.script)
+			toolchain := service.DefaultToolchain()
+			toolchain.Qpdf = path
+
+			count, err := service.NewQpdfPageCountService(toolchain).PageCount(context.Background(), entity.NewFile("test", "test.pdf"))
+			if tt.expectedErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if count != tt.expectedCount {
+					t.Errorf("expected %d pages, got %d", tt.expectedCount, count)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if strings.Contains(err.Error(), "test.pdf") {
+				t.Errorf("expected stderr to be left out of the error, got %q", err)
+			}
+		})
+	}
+}
+
+func TestQpdfDecryptService_InvalidPassword(t *testing.T) {
+	path, _ := writeFakeCommand(t, "echo 'test.pdf: invalid password' >&2; exit 2")
+	toolchain := service.DefaultToolchain()
+	toolchain.Qpdf = path
+
+	err := service.NewQpdfDecryptService(toolchain, metrics.New()).Decrypt(context.Background(), entity.NewFile("test", "test.pdf"), "wrong")
+	if !errors.Is(err, usecase.ErrInvalidPassword) {
+		t.Errorf("expected error %v, got %v", usecase.ErrInvalidPassword, err)
+	}
+}
+
+func TestImageMagickConvertService_DocumentErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		expectedErr error
+	}{
+		{
+			name:        "Unsupported Document Test",
+			script:      "echo 'convert: no decode delegate for this image format' >&2; exit 1",
+			expectedErr: usecase.ErrUnsupportedDocument,
+		},
+		{
+			name:        "Ghostscript Error Test",
+			script:      "echo '   **** Error: Cannot find a %%EOF marker anywhere in the file.' >&2; exit 1",
+			expectedErr: usecase.ErrCorruptDocument,
+		},
+		{
+			name:        "Too Many Pages Test",
+			script:      "echo 'convert: list length exceeds limit @ error/list.c/AppendImageToList/87.' >&2; exit 1",
+			expectedErr: usecase.ErrTooManyPages,
+		},
+		{
+			name:        "Policy Time Limit Test",
+			script:      "echo 'convert: time limit exceeded @ fatal/cache.c/GetImagePixelCache/1683.' >&2; exit 1",
+			expectedErr: usecase.ErrConversionTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolchain, _ := writeFakeMagick(t, tt.script)
+			convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{}, metrics.New())
+
+			_, err := convertService.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+			})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if err != nil && strings.Contains(err.Error(), "after 0s") {
+				t.Errorf("expected no timeout duration without a timeout, got %q", err)
+			}
+		})
+	}
+}
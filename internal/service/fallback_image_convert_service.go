@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.ImageConvertService = &FallbackImageConvertService{}
+
+// finalErrors are caused by the request or the server state, retrying them
+// with another renderer cannot succeed
+var finalErrors = []error{
+	usecase.ErrInvalidPassword,
+	usecase.ErrPasswordRequired,
+	usecase.ErrInvalidPageRange,
+	usecase.ErrInvalidSize,
+	usecase.ErrUnsupportedFormat,
+	usecase.ErrUnsupportedRenderer,
+	usecase.ErrTooManyPages,
+	usecase.ErrOutputTooLarge,
+	usecase.ErrServerBusy,
+	usecase.ErrResourceLimitExceeded,
+	usecase.ErrConversionTimeout,
+}
+
+// FallbackImageConvertService implements the ImageConvertService interface
+// by trying an ordered list of renderers, the next renderer is used when the
+// previous one fails to render the document
+type FallbackImageConvertService struct {
+	next      usecase.ImageConvertService
+	renderers []usecase.Renderer
+}
+
+// NewFallbackImageConvertService tries renderers in order through next when
+// the conversion does not select a renderer
+func NewFallbackImageConvertService(next usecase.ImageConvertService, renderers []usecase.Renderer) *FallbackImageConvertService {
+	return &FallbackImageConvertService{
+		next:      next,
+		renderers: renderers,
+	}
+}
+
+// Convert only falls back on rendering failures such as a corrupt document
+// or a crashed renderer, a conversion selecting a renderer never falls back
+func (s *FallbackImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	if options.Renderer != usecase.RendererDefault || len(s.renderers) == 0 {
+		return s.next.Convert(ctx, file, options)
+	}
+
+	var err error
+	for i, renderer := range s.renderers {
+		options.Renderer = renderer
+
+		var images []usecase.Image
+		images, err = s.next.Convert(ctx, file, options)
+		if err == nil {
+			return images, nil
+		}
+
+		isLast := i == len(s.renderers)-1
+		if isLast || !isFallbackError(ctx, err) {
+			break
+		}
+
+		slog.WarnContext(ctx, "Renderer failed, falling back",
+			"renderer", renderer,
+			"next_renderer", s.renderers[i+1],
+			"error", err,
+		)
+	}
+
+	return nil, err
+}
+
+// isFallbackError reports whether another renderer may succeed after err
+func isFallbackError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for _, final := range finalErrors {
+		if errors.Is(err, final) {
+			return false
+		}
+	}
+
+	return true
+}
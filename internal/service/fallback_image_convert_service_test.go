@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// failingRendererImageConvertService fails the conversions of the renderers
+// in errs and records the renderers it is called with
+type failingRendererImageConvertService struct {
+	errs  map[usecase.Renderer]error
+	calls []usecase.Renderer
+}
+
+func (s *failingRendererImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	s.calls = append(s.calls, options.Renderer)
+	if err, ok := s.errs[options.Renderer]; ok {
+		return nil, err
+	}
+
+	return []usecase.Image{{Page: 1, Renderer: options.Renderer}}, nil
+}
+
+func TestFallbackImageConvertService_Convert(t *testing.T) {
+	renderers := []usecase.Renderer{usecase.RendererGhostscript, usecase.RendererMutool, usecase.RendererPdftoppm}
+	crashed := errors.New("exit status 139")
+
+	tests := []struct {
+		name             string
+		renderer         usecase.Renderer
+		errs             map[usecase.Renderer]error
+		expectedCalls    []usecase.Renderer
+		expectedRenderer usecase.Renderer
+		expectedError    error
+	}{
+		{
+			name:             "First Renderer Succeeds Test",
+			expectedCalls:    []usecase.Renderer{usecase.RendererGhostscript},
+			expectedRenderer: usecase.RendererGhostscript,
+		},
+		{
+			name:             "Fallback On Crash Test",
+			errs:             map[usecase.Renderer]error{usecase.RendererGhostscript: crashed},
+			expectedCalls:    []usecase.Renderer{usecase.RendererGhostscript, usecase.RendererMutool},
+			expectedRenderer: usecase.RendererMutool,
+		},
+		{
+			name: "Fallback On Corrupt Document Test",
+			errs: map[usecase.Renderer]error{
+				usecase.RendererGhostscript: usecase.ErrCorruptDocument,
+				usecase.RendererMutool:      usecase.ErrCorruptDocument,
+			},
+			expectedCalls:    renderers,
+			expectedRenderer: usecase.RendererPdftoppm,
+		},
+		{
+			name: "Every Renderer Fails Test",
+			errs: map[usecase.Renderer]error{
+				usecase.RendererGhostscript: crashed,
+				usecase.RendererMutool:      crashed,
+				usecase.RendererPdftoppm:    usecase.ErrCorruptDocument,
+			},
+			expectedCalls: renderers,
+			expectedError: usecase.ErrCorruptDocument,
+		},
+		{
+			name:          "No Fallback On Invalid Password Test",
+			errs:          map[usecase.Renderer]error{usecase.RendererGhostscript: usecase.ErrInvalidPassword},
+			expectedCalls: []usecase.Renderer{usecase.RendererGhostscript},
+			expectedError: usecase.ErrInvalidPassword,
+		},
+		{
+			name:          "No Fallback On Resource Limit Test",
+			errs:          map[usecase.Renderer]error{usecase.RendererGhostscript: usecase.ErrResourceLimitExceeded},
+			expectedCalls: []usecase.Renderer{usecase.RendererGhostscript},
+			expectedError: usecase.ErrResourceLimitExceeded,
+		},
+		{
+			name:          "No Fallback For Selected Renderer Test",
+			renderer:      usecase.RendererMutool,
+			errs:          map[usecase.Renderer]error{usecase.RendererMutool: crashed},
+			expectedCalls: []usecase.Renderer{usecase.RendererMutool},
+			expectedError: crashed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &failingRendererImageConvertService{errs: tt.errs}
+			converter := service.NewFallbackImageConvertService(next, renderers)
+
+			images, err := converter.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+				Renderer: tt.renderer,
+			})
+
+			if !slices.Equal(next.calls, tt.expectedCalls) {
+				t.Errorf("expected renderers %v to be tried, got %v", tt.expectedCalls, next.calls)
+			}
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if images[0].Renderer != tt.expectedRenderer {
+				t.Errorf("expected renderer %q, got %q", tt.expectedRenderer, images[0].Renderer)
+			}
+		})
+	}
+}
+
+func TestFallbackImageConvertService_CancelledContext(t *testing.T) {
+	next := &failingRendererImageConvertService{errs: map[usecase.Renderer]error{
+		usecase.RendererGhostscript: context.Canceled,
+	}}
+	converter := service.NewFallbackImageConvertService(next, []usecase.Renderer{usecase.RendererGhostscript, usecase.RendererMutool})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := converter.Convert(ctx, entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if len(next.calls) != 1 {
+		t.Errorf("expected no fallback after cancellation, got %v", next.calls)
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+)
+
+// DefaultDeniedNetworks covers loopback, private, link-local, shared,
+// documentation and reserved ranges which must not be reachable from user
+// supplied URLs, including the IPv6 translation ranges embedding IPv4
+// addresses. IPv4-mapped IPv6 addresses are unmapped before the check.
+var DefaultDeniedNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// DefaultAllowedContentTypes are accepted as PDF documents, a response
+// without Content-Type is always accepted
+var DefaultAllowedContentTypes = []string{
+	"application/pdf",
+	"application/octet-stream",
+	"binary/octet-stream",
+}
+
+// HttpDocumentFetcherOptions restricts which documents can be fetched.
+// AllowedHosts and DeniedHosts match the exact host name, or the host and
+// any subdomain when prefixed with "*.", and an empty AllowedHosts allows
+// every host.
+// Connections to DeniedNetworks are refused unless the address is also in
+// AllowedNetworks. Nil DeniedNetworks and AllowedContentTypes fall back to
+// the defaults. Zero MaxBytes and Timeout are disabled while zero
+// MaxRedirects refuses every redirect.
+type HttpDocumentFetcherOptions struct {
+	MaxBytes            int64
+	Timeout             time.Duration
+	MaxRedirects        int
+	AllowedHosts        []string
+	DeniedHosts         []string
+	AllowedNetworks     []netip.Prefix
+	DeniedNetworks      []netip.Prefix
+	AllowedContentTypes []string
+}
+
+// HttpDocumentFetcher implements the usecase.DocumentFetcher interface with
+// a guarded client which checks every resolved address before connecting
+type HttpDocumentFetcher struct {
+	client  *http.Client
+	options HttpDocumentFetcherOptions
+}
+
+func NewHttpDocumentFetcher(options HttpDocumentFetcherOptions) *HttpDocumentFetcher {
+	if options.AllowedContentTypes == nil {
+		options.AllowedContentTypes = DefaultAllowedContentTypes
+	}
+
+	fetcher := &HttpDocumentFetcher{options: options}
+	fetcher.client = NewGuardedHttpClient(GuardedHttpClientOptions{
+		Timeout:         options.Timeout,
+		MaxRedirects:    options.MaxRedirects,
+		AllowedNetworks: options.AllowedNetworks,
+		DeniedNetworks:  options.DeniedNetworks,
+		CheckRedirect: func(target *url.URL) error {
+			return fetcher.checkHost(target.Hostname())
+		},
+	})
+
+	return fetcher
+}
+
+// Fetch downloads the document, the returned reader fails with
+// usecase.ErrFileTooLarge once more than MaxBytes are read
+func (f *HttpDocumentFetcher) Fetch(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("%w: expected an absolute http or https url", usecase.ErrFetchForbidden)
+	}
+
+	if err := f.checkHost(target.Hostname()); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid request", usecase.ErrFetchFailed)
+	}
+	req.Header.Set("Accept", "application/pdf")
+	req.Header.Set("User-Agent", "pdf64-fetcher")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		var policyErr *policyError
+		if errors.As(err, &policyErr) {
+			return nil, fetchPolicyError(policyErr.err)
+		}
+
+		httplog.LogEntrySetField(ctx, "fetch_error", slog.StringValue(err.Error()))
+
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("%w: request timed out", usecase.ErrFetchFailed)
+		}
+
+		return nil, fmt.Errorf("%w: request failed", usecase.ErrFetchFailed)
+	}
+
+	if err := f.checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if f.options.MaxBytes <= 0 {
+		return resp.Body, nil
+	}
+
+	return &limitedReadCloser{
+		ReadCloser: resp.Body,
+		remaining:  f.options.MaxBytes,
+		limit:      f.options.MaxBytes,
+	}, nil
+}
+
+func (f *HttpDocumentFetcher) checkResponse(resp *http.Response) error {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: unexpected status %d", usecase.ErrFetchFailed, resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !slices.Contains(f.options.AllowedContentTypes, mediaType) {
+			return fmt.Errorf("%w: unsupported content type %q", usecase.ErrFetchFailed, contentType)
+		}
+	}
+
+	if f.options.MaxBytes > 0 && resp.ContentLength > f.options.MaxBytes {
+		return fmt.Errorf("%w: exceeds %d bytes", usecase.ErrFileTooLarge, f.options.MaxBytes)
+	}
+
+	return nil
+}
+
+func (f *HttpDocumentFetcher) checkHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if len(f.options.AllowedHosts) > 0 && !matchHosts(f.options.AllowedHosts, host) {
+		return fmt.Errorf("%w: host %s is not allowed", usecase.ErrFetchForbidden, host)
+	}
+
+	if matchHosts(f.options.DeniedHosts, host) {
+		return fmt.Errorf("%w: host %s is denied", usecase.ErrFetchForbidden, host)
+	}
+
+	return nil
+}
+
+// fetchPolicyError reports the rejections of the guarded client as fetch
+// errors, the host rules already return them
+func fetchPolicyError(err error) error {
+	switch {
+	case errors.Is(err, errTooManyRedirects):
+		return fmt.Errorf("%w: %w", usecase.ErrFetchFailed, err)
+	case errors.Is(err, errDeniedDestination):
+		return fmt.Errorf("%w: %w", usecase.ErrFetchForbidden, err)
+	default:
+		return err
+	}
+}
+
+// matchHosts matches the exact host, or the host and its subdomains when the
+// pattern starts with "*."
+func matchHosts(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if base, ok := strings.CutPrefix(pattern, "*."); ok && strings.HasSuffix(host, "."+base) {
+			return true
+		}
+
+		if host == strings.TrimPrefix(pattern, "*.") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limitedReadCloser fails the read once the body exceeds the limit
+type limitedReadCloser struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("%w: exceeds %d bytes", usecase.ErrFileTooLarge, r.limit)
+	}
+
+	return n, err
+}
@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var loopbackNetworks = []netip.Prefix{
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("::1/128"),
+}
+
+func TestHttpDocumentFetcher_Fetch(t *testing.T) {
+	document := "%PDF-1.5\n%%EOF\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/document.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, document)
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		io.WriteString(w, document)
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<html></html>")
+	})
+	mux.HandleFunc("/large.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		io.WriteString(w, strings.Repeat("0", 1024))
+	})
+	mux.HandleFunc("/chunked.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		for range 4 {
+			io.WriteString(w, strings.Repeat("0", 256))
+			w.(http.Flusher).Flush()
+		}
+	})
+	mux.HandleFunc("/missing.pdf", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/slow.pdf", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	mux.HandleFunc("/redirect/{count}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("count") == "0" {
+			http.Redirect(w, r, "/document.pdf", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, "/redirect/0", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect-away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://denied.example.com/document.pdf", http.StatusFound)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowLoopback := service.HttpDocumentFetcherOptions{
+		AllowedNetworks: loopbackNetworks,
+		MaxRedirects:    3,
+	}
+
+	tests := []struct {
+		name            string
+		path            string
+		url             string
+		options         service.HttpDocumentFetcherOptions
+		expectedContent string
+		expectedError   error
+	}{
+		{
+			name:            "fetch document",
+			path:            "/document.pdf",
+			options:         allowLoopback,
+			expectedContent: document,
+		},
+		{
+			name:            "fetch document without content type",
+			path:            "/untyped",
+			options:         allowLoopback,
+			expectedContent: document,
+		},
+		{
+			name:            "follow redirects within limit",
+			path:            "/redirect/1",
+			options:         allowLoopback,
+			expectedContent: document,
+		},
+		{
+			name:          "reject private network by default",
+			path:          "/document.pdf",
+			options:       service.HttpDocumentFetcherOptions{},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name:          "reject NAT64 address of a private network",
+			url:           "http://[64:ff9b::7f00:1]:" + serverURL.Port() + "/document.pdf",
+			options:       allowLoopback,
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name:          "reject IPv4-mapped address of a private network",
+			url:           "http://[::ffff:127.0.0.1]:" + serverURL.Port() + "/document.pdf",
+			options:       service.HttpDocumentFetcherOptions{},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name: "reject host outside allow list",
+			path: "/document.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				AllowedHosts:    []string{"*.example.com"},
+			},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name: "fetch from wildcard base host",
+			url:  "http://localhost:" + serverURL.Port() + "/document.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				AllowedHosts:    []string{"*.localhost"},
+			},
+			expectedContent: document,
+		},
+		{
+			name: "reject wildcard without a dot",
+			url:  "http://localhost:" + serverURL.Port() + "/document.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				AllowedHosts:    []string{"*host"},
+			},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name: "reject denied host",
+			path: "/document.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				DeniedHosts:     []string{serverURL.Hostname()},
+			},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name: "reject redirect to denied host",
+			path: "/redirect-away",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				MaxRedirects:    3,
+				DeniedHosts:     []string{"denied.example.com"},
+			},
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name:          "reject unsupported scheme",
+			url:           "file:///etc/passwd",
+			options:       allowLoopback,
+			expectedError: usecase.ErrFetchForbidden,
+		},
+		{
+			name: "reject too many redirects",
+			path: "/redirect/1",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				MaxRedirects:    1,
+			},
+			expectedError: usecase.ErrFetchFailed,
+		},
+		{
+			name:          "reject unexpected status",
+			path:          "/missing.pdf",
+			options:       allowLoopback,
+			expectedError: usecase.ErrFetchFailed,
+		},
+		{
+			name:          "reject unsupported content type",
+			path:          "/page.html",
+			options:       allowLoopback,
+			expectedError: usecase.ErrFetchFailed,
+		},
+		{
+			name: "reject slow response",
+			path: "/slow.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				Timeout:         50 * time.Millisecond,
+			},
+			expectedError: usecase.ErrFetchFailed,
+		},
+		{
+			name: "reject large content length",
+			path: "/large.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				MaxBytes:        512,
+			},
+			expectedError: usecase.ErrFileTooLarge,
+		},
+		{
+			name: "reject large chunked body",
+			path: "/chunked.pdf",
+			options: service.HttpDocumentFetcherOptions{
+				AllowedNetworks: loopbackNetworks,
+				MaxBytes:        512,
+			},
+			expectedError: usecase.ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.url
+			if target == "" {
+				target = server.URL + tt.path
+			}
+
+			fetcher := service.NewHttpDocumentFetcher(tt.options)
+			body, err := fetcher.Fetch(context.Background(), target)
+			if err == nil {
+				defer body.Close()
+
+				var content []byte
+				content, err = io.ReadAll(body)
+				if err == nil && string(content) != tt.expectedContent {
+					t.Errorf("expected content %q, got %q", tt.expectedContent, content)
+				}
+			}
+
+			if tt.expectedError == nil && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
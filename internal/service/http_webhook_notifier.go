@@ -0,0 +1,63 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body
+const SignatureHeader = "X-Pdf64-Signature"
+
+// HttpWebhookNotifier implements the usecase.WebhookNotifier interface by
+// posting the signed payload as JSON
+type HttpWebhookNotifier struct {
+	client *http.Client
+	secret []byte
+}
+
+func NewHttpWebhookNotifier(client *http.Client, secret string) *HttpWebhookNotifier {
+	return &HttpWebhookNotifier{
+		client: client,
+		secret: []byte(secret),
+	}
+}
+
+// Notify posts the payload once, any non-2xx response is reported as an error
+func (n *HttpWebhookNotifier) Notify(ctx context.Context, url string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pdf64-webhook")
+	req.Header.Set(SignatureHeader, "sha256="+Sign(n.secret, payload))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body to allow the connection to be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook receiver responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 of the payload
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/service"
+)
+
+func TestHttpWebhookNotifier_Notify(t *testing.T) {
+	tests := []struct {
+		name           string
+		receiverStatus int
+		expectedError  bool
+	}{
+		{
+			name:           "accepted by receiver",
+			receiverStatus: http.StatusOK,
+			expectedError:  false,
+		},
+		{
+			name:           "accepted without content",
+			receiverStatus: http.StatusNoContent,
+			expectedError:  false,
+		},
+		{
+			name:           "rejected by receiver",
+			receiverStatus: http.StatusInternalServerError,
+			expectedError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := []byte(`{"id":"test-id","status":"succeeded"}`)
+
+			var body []byte
+			var signature, contentType string
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				signature = r.Header.Get(service.SignatureHeader)
+				contentType = r.Header.Get("Content-Type")
+				w.WriteHeader(tt.receiverStatus)
+			}))
+			defer receiver.Close()
+
+			notifier := service.NewHttpWebhookNotifier(receiver.Client(), "secret")
+			statusCode, err := notifier.Notify(context.Background(), receiver.URL, payload)
+
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+
+			if statusCode != tt.receiverStatus {
+				t.Errorf("expected status code %d, got %d", tt.receiverStatus, statusCode)
+			}
+
+			if string(body) != string(payload) {
+				t.Errorf("expected payload %s, got %s", payload, body)
+			}
+
+			if contentType != "application/json" {
+				t.Errorf("expected content type application/json, got %s", contentType)
+			}
+
+			expectedSignature := "sha256=" + service.Sign([]byte("secret"), payload)
+			if signature != expectedSignature {
+				t.Errorf("expected signature %s, got %s", expectedSignature, signature)
+			}
+		})
+	}
+}
+
+func TestHttpWebhookNotifier_NotifyUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	notifier := service.NewHttpWebhookNotifier(http.DefaultClient, "secret")
+	statusCode, err := notifier.Notify(context.Background(), receiver.URL, []byte(`{}`))
+	if err == nil {
+		t.Fatal("expected error for unreachable receiver")
+	}
+
+	if statusCode != 0 {
+		t.Errorf("expected status code 0, got %d", statusCode)
+	}
+}
+
+func TestHttpWebhookNotifier_NotifyGuarded(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hook", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/hook", http.StatusTemporaryRedirect)
+	})
+
+	receiver := httptest.NewServer(mux)
+	defer receiver.Close()
+
+	tests := []struct {
+		name          string
+		path          string
+		options       service.GuardedHttpClientOptions
+		expectedError bool
+	}{
+		{
+			name:    "allowed network",
+			path:    "/hook",
+			options: service.GuardedHttpClientOptions{AllowedNetworks: loopbackNetworks},
+		},
+		{
+			name:          "reject private network by default",
+			path:          "/hook",
+			options:       service.GuardedHttpClientOptions{},
+			expectedError: true,
+		},
+		{
+			name:          "reject redirect",
+			path:          "/redirect",
+			options:       service.GuardedHttpClientOptions{AllowedNetworks: loopbackNetworks},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := service.NewHttpWebhookNotifier(service.NewGuardedHttpClient(tt.options), "secret")
+			statusCode, err := notifier.Notify(context.Background(), receiver.URL+tt.path, []byte(`{}`))
+
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectedError, err)
+			}
+
+			if tt.expectedError && statusCode != 0 {
+				t.Errorf("expected status code 0, got %d", statusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// testImageConvertServiceContract converts the fixture PDF with every option
+// an ImageConvertService implementation must support
+func testImageConvertServiceContract(t *testing.T, convertService usecase.ImageConvertService) {
+	t.Helper()
+
+	// Use the real PDF file from fixtures
+	// Get the absolute path to the fixtures directory from project root
+	// First find the project root directory
+	_, currentFile, _, _ := runtime.Caller(0)
+	projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(currentFile)))
+	pdfPath := filepath.Join(projectRoot, "fixtures", "dummy.pdf")
+
+	// Verify the test PDF file exists
+	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
+		t.Fatalf("Test PDF file not found at %s", pdfPath)
+	}
+
+	// Create file entity
+	file := entity.NewFile("test-id", pdfPath)
+
+	// Test cases for different conversion options
+	testCases := []struct {
+		name    string
+		options usecase.ImageConvertOptions
+	}{
+		{
+			name: "Standard conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Merge:   false,
+			},
+		},
+		{
+			name: "Merged conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Merge:   true,
+			},
+		},
+		{
+			name: "PNG conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Format:  usecase.ImageFormatPNG,
+			},
+		},
+		{
+			name: "WebP conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Format:  usecase.ImageFormatWebP,
+			},
+		},
+		{
+			name: "Resized conversion",
+			options: usecase.ImageConvertOptions{
+				Density:   "150",
+				Quality:   90,
+				Width:     200,
+				Height:    200,
+				MaxPixels: 20000,
+			},
+		},
+		{
+			name: "Selected pages conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Pages:   []int{1},
+			},
+		},
+		{
+			name: "TIFF conversion",
+			options: usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Format:  usecase.ImageFormatTIFF,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runConversionTest(t, file, convertService, tc.options)
+		})
+	}
+}
+
+func runConversionTest(t *testing.T, file *entity.File, convertService usecase.ImageConvertService, options usecase.ImageConvertOptions) {
+	format := options.Format
+	if format == "" {
+		format = usecase.ImageFormatJPEG
+	}
+	prefix := "data:" + format.MimeType() + ";base64,"
+
+	// Convert the PDF to images
+	images, err := convertService.Convert(context.Background(), file, options)
+	if err != nil {
+		t.Fatalf("Failed to convert PDF to images: %v", err)
+	}
+
+	// Verify we got images
+	if len(images) == 0 {
+		t.Error("Expected at least one image, got none")
+	}
+
+	// If merge is enabled, we should only get one image
+	if options.Merge && len(images) > 1 {
+		t.Errorf("Expected only one merged image, got %d", len(images))
+	}
+
+	// Check that the returned images are valid
+	for i, image := range images {
+		if image.Width <= 0 || image.Height <= 0 {
+			t.Errorf("Image %d has invalid size %dx%d", i, image.Width, image.Height)
+		}
+
+		if options.Width > 0 && image.Width > options.Width {
+			t.Errorf("Image %d width %d exceeds %d", i, image.Width, options.Width)
+		}
+
+		if options.Height > 0 && image.Height > options.Height {
+			t.Errorf("Image %d height %d exceeds %d", i, image.Height, options.Height)
+		}
+
+		if options.MaxPixels > 0 && image.Width*image.Height > options.MaxPixels {
+			t.Errorf("Image %d area %d exceeds %d pixels", i, image.Width*image.Height, options.MaxPixels)
+		}
+
+		if image.MimeType != format.MimeType() {
+			t.Errorf("Image %d has MIME type %s, expected %s", i, image.MimeType, format.MimeType())
+		}
+
+		if !options.Merge && image.Page != i+1 {
+			t.Errorf("Image %d has page %d, expected %d", i, image.Page, i+1)
+		}
+
+		if len(image.Data) == 0 {
+			t.Errorf("Image %d is empty", i)
+			continue
+		}
+
+		// Check if the data URI starts with the image prefix
+		if !strings.HasPrefix(image.DataURI(), prefix) {
+			t.Errorf("Image %d does not have valid image data prefix", i)
+			continue
+		}
+
+		t.Logf("Successfully verified image %d", i)
+	}
+}
@@ -2,124 +2,482 @@ package service
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/elct9620/pdf64/internal/entity"
 	"github.com/elct9620/pdf64/internal/usecase"
 	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ImageMagickConvertService implements the ImageConvertService interface
 // using ImageMagick's convert command
-type ImageMagickConvertService struct{}
+type ImageMagickConvertService struct {
+	toolchain Toolchain
+	tempDir   string
+	limits    ResourceLimits
+	metrics   usecase.ConvertMetrics
+	// processes holds a slot for each running convert command, it is shared
+	// by every conversion and unbounded when nil
+	processes chan struct{}
+}
+
+// NewImageMagickConvertService creates a new ImageMagickConvertService which
+// renders into temporary directories under tempDir, or the system default
+// when empty, and applies the limits to each conversion
+func NewImageMagickConvertService(toolchain Toolchain, tempDir string, limits ResourceLimits, metrics usecase.ConvertMetrics) *ImageMagickConvertService {
+	var processes chan struct{}
+	if limits.Processes > 0 {
+		processes = make(chan struct{}, limits.Processes)
+	}
 
-// NewImageMagickConvertService creates a new ImageMagickConvertService
-func NewImageMagickConvertService() *ImageMagickConvertService {
-	return &ImageMagickConvertService{}
+	return &ImageMagickConvertService{
+		toolchain: toolchain,
+		tempDir:   tempDir,
+		limits:    limits,
+		metrics:   metrics,
+		processes: processes,
+	}
 }
 
-// Convert converts a PDF file to images and returns them as base64 encoded strings
-func (s *ImageMagickConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]string, error) {
+// Convert converts a PDF file to images and returns their content. Running
+// the convert commands is measured as the rasterize phase and collecting the
+// encoded images as the encode phase.
+func (s *ImageMagickConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
 	// Create temporary directory for output images
-	tmpDir, err := os.MkdirTemp("", "pdf64-images-*")
+	tmpDir, err := os.MkdirTemp(s.tempDir, "pdf64-images-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	// Prepare output path pattern
-	outputPattern := filepath.Join(tmpDir, "page-%d.jpg")
+	limitCtx, cancel := s.limits.withTimeout(ctx)
+	defer cancel()
+
+	startedAt := time.Now()
+	err = s.renderPages(ctx, limitCtx, file, options, tmpDir)
+	s.metrics.ObservePhase(usecase.ConvertPhaseRasterize, time.Since(startedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	encodeStartedAt := time.Now()
+	defer func() {
+		s.metrics.ObservePhase(usecase.ConvertPhaseEncode, time.Since(encodeStartedAt))
+	}()
+
+	imagePaths, err := outputPaths(tmpDir, options.Merge)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.readImages(ctx, imagePaths, options)
+}
+
+// isParallel is true when the pages are rendered by separate processes, a
+// merged image is always rendered by a single process
+func (s *ImageMagickConvertService) isParallel(options usecase.ImageConvertOptions) bool {
+	return s.limits.Processes > 1 && !options.Merge && len(options.Pages) > 1
+}
+
+// renderBatch is a convert command rendering pages into output
+type renderBatch struct {
+	pages  []int
+	output string
+}
+
+// batches renders every page by a single command, or each page by its own
+// command named page-i after its index to keep the page order
+func (s *ImageMagickConvertService) batches(options usecase.ImageConvertOptions, dir string) []renderBatch {
+	if !s.isParallel(options) {
+		return []renderBatch{{pages: options.Pages, output: s.outputPath(dir, options, -1)}}
+	}
+
+	batches := make([]renderBatch, 0, len(options.Pages))
+	for i, page := range options.Pages {
+		batches = append(batches, renderBatch{pages: []int{page}, output: s.outputPath(dir, options, i)})
+	}
+
+	return batches
+}
+
+// renderPages runs the convert commands of the batches, each command waits
+// for a process slot shared with the other conversions so at most
+// limits.Processes commands run at once. The first failure stops the
+// remaining batches.
+func (s *ImageMagickConvertService) renderPages(ctx, limitCtx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) error {
+	renderCtx, cancel := context.WithCancel(limitCtx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for _, batch := range s.batches(options, dir) {
+		release, err := s.acquireProcess(renderCtx)
+		if err != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+
+			if err := s.render(ctx, renderCtx, file, options, batch.pages, batch.output); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// The timeout may expire while waiting for a free slot
+	if err := limitCtx.Err(); err != nil {
+		if convErr := conversionError(ctx, limitCtx, s.limits, err, "", imageMagickErrorMessages); convErr != nil {
+			return convErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// acquireProcess waits for a free process slot, the returned function
+// releases it
+func (s *ImageMagickConvertService) acquireProcess(ctx context.Context) (func(), error) {
+	if s.processes == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case s.processes <- struct{}{}:
+		return func() { <-s.processes }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// outputPath names the output of the page at index, a negative index writes
+// every page through the page-%d pattern
+func (s *ImageMagickConvertService) outputPath(dir string, options usecase.ImageConvertOptions, index int) string {
+	format := options.Format
+	if format == "" {
+		format = usecase.ImageFormatJPEG
+	}
+
+	if options.Merge {
+		return filepath.Join(dir, "merged."+format.Extension())
+	}
+
+	if index < 0 {
+		return filepath.Join(dir, "page-%d."+format.Extension())
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("page-%d.%s", index, format.Extension()))
+}
+
+// render runs a single convert command rendering the pages into output,
+// limitCtx bounds the process and failures are classified against ctx
+func (s *ImageMagickConvertService) render(ctx, limitCtx context.Context, file *entity.File, options usecase.ImageConvertOptions, pages []int, output string) error {
+	format := options.Format
+	if format == "" {
+		format = usecase.ImageFormatJPEG
+	}
 
 	// Prepare convert command arguments
-	args := []string{
+	args := append(s.limits.imageMagickArgs(),
 		"-density", options.Density,
 		"-quality", fmt.Sprintf("%d", options.Quality),
-	}
+	)
+
+	args = append(args, pageSelectedPath(file.Path(), pages))
+	args = append(args, resizeArgs(options)...)
 
 	// If merge is enabled, we'll append all pages into a single image
 	if options.Merge {
-		// For merged output, we use a single file name
-		outputPattern = filepath.Join(tmpDir, "merged.jpg")
-		args = append(args, file.Path(), "-append", outputPattern)
-	} else {
-		args = append(args, file.Path(), outputPattern)
+		args = append(args, "-append")
 	}
+	args = append(args, output)
 
-	cliPath, err := exec.LookPath("magick")
+	cmd, err := s.toolchain.imageMagickCommand(limitCtx, "convert", args...)
 	if err != nil {
-		cliPath, err = exec.LookPath("convert")
-		if err != nil {
-			return nil, fmt.Errorf("failed to find ImageMagick command: %w", err)
-		}
+		return err
 	}
 
-	// Try to execute with magick command (ImageMagick 7)
-	cmd := exec.CommandContext(ctx, cliPath, args...)
-
 	// Capture stdout and stderr
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	err = runCommand(ctx, "imagemagick convert", cmd, s.limits,
+		attribute.String("pdf64.density", options.Density),
+		attribute.Int("pdf64.quality", options.Quality),
+		attribute.String("pdf64.format", string(format)),
+		attribute.Int("pdf64.page_count", len(pages)),
+		attribute.Bool("pdf64.merge", options.Merge),
+	)
+	if err != nil {
+		// Pages stopped after another page failed are not failures themselves
+		if errors.Is(limitCtx.Err(), context.Canceled) && ctx.Err() == nil {
+			return limitCtx.Err()
+		}
+
+		s.metrics.ObserveSubprocessFailure("convert")
 		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
-		return nil, fmt.Errorf("failed to convert PDF to images: %w", err)
+
+		if convErr := conversionError(ctx, limitCtx, s.limits, err, stderr.String(), imageMagickErrorMessages); convErr != nil {
+			return convErr
+		}
+
+		return fmt.Errorf("failed to convert PDF to images: %w", err)
 	}
 
-	// Collect paths of generated images
-	var imagePaths []string
+	return nil
+}
+
+// encode converts the rendered pages into the requested format applying the
+// resize and merge options, the images are written into dir
+func (s *ImageMagickConvertService) encode(ctx context.Context, pagePaths []string, options usecase.ImageConvertOptions, dir string) ([]string, error) {
+	format := options.Format
+	if format == "" {
+		format = usecase.ImageFormatJPEG
+	}
+
+	args := append(s.limits.imageMagickArgs(), pagePaths...)
+	args = append(args, resizeArgs(options)...)
+	args = append(args, "-quality", strconv.Itoa(options.Quality))
 
 	if options.Merge {
-		// In merge mode, we only have one output file
-		mergedPath := filepath.Join(tmpDir, "merged.jpg")
-		if _, err := os.Stat(mergedPath); err == nil {
-			imagePaths = append(imagePaths, mergedPath)
-		} else {
-			return nil, fmt.Errorf("failed to find merged output image: %w", err)
-		}
+		args = append(args, "-append", filepath.Join(dir, "merged."+format.Extension()))
 	} else {
-		// In normal mode, collect all page-* files
-		files, err := os.ReadDir(tmpDir)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read temporary directory: %w", err)
-		}
+		args = append(args, filepath.Join(dir, "page-%d."+format.Extension()))
+	}
 
-		for _, f := range files {
-			if !f.IsDir() && strings.HasPrefix(f.Name(), "page-") {
-				imagePath := filepath.Join(tmpDir, f.Name())
-				imagePaths = append(imagePaths, imagePath)
-			}
+	cmd, err := s.toolchain.imageMagickCommand(ctx, "convert", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := runCommand(ctx, "imagemagick encode", cmd, s.limits, attribute.Int("pdf64.image_count", len(pagePaths))); err != nil {
+		s.metrics.ObserveSubprocessFailure("convert")
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if limit := exceededLimit(err, stderr.String()); limit != "" {
+			return nil, fmt.Errorf("%w: %s", usecase.ErrResourceLimitExceeded, limit)
 		}
 
-		// Sort the image paths to ensure correct page order
-		// (This is important because ReadDir doesn't guarantee order)
-		sort.Strings(imagePaths)
+		return nil, fmt.Errorf("failed to encode images: %w", err)
 	}
 
-	// Convert images to base64
-	var base64Images []string
-	for _, imagePath := range imagePaths {
-		// Read image file
+	return outputPaths(dir, options.Merge)
+}
+
+// readImages loads the images in page order and identifies their sizes
+func (s *ImageMagickConvertService) readImages(ctx context.Context, imagePaths []string, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	format := options.Format
+	if format == "" {
+		format = usecase.ImageFormatJPEG
+	}
+
+	sizes, err := s.imageSizes(ctx, imagePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []usecase.Image
+	var written int64
+	for i, imagePath := range imagePaths {
+		// Check the size before reading to keep the output within the limit
+		info, err := os.Stat(imagePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image file: %w", err)
+		}
+
+		written += info.Size()
+		if options.MaxOutputBytes > 0 && written > options.MaxOutputBytes {
+			return nil, fmt.Errorf("%w: exceeds %d bytes", usecase.ErrOutputTooLarge, options.MaxOutputBytes)
+		}
+
 		imageData, err := os.ReadFile(imagePath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read image file: %w", err)
 		}
 
-		// Encode to base64
-		base64Data := base64.StdEncoding.EncodeToString(imageData)
+		images = append(images, usecase.Image{
+			Page:     imagePage(i, options),
+			Width:    sizes[i].width,
+			Height:   sizes[i].height,
+			MimeType: format.MimeType(),
+			Data:     imageData,
+		})
+	}
 
-		// Add data URI prefix
-		base64Image := fmt.Sprintf("data:image/jpeg;base64,%s", base64Data)
+	return images, nil
+}
 
-		base64Images = append(base64Images, base64Image)
+// outputPaths collects the merged image or the page-%d images of dir sorted
+// by their number
+func outputPaths(dir string, merge bool) ([]string, error) {
+	if merge {
+		// In merge mode, we only have one output file
+		mergedPaths, err := filepath.Glob(filepath.Join(dir, "merged.*"))
+		if err != nil || len(mergedPaths) == 0 {
+			return nil, fmt.Errorf("failed to find merged output image: %w", os.ErrNotExist)
+		}
+		return mergedPaths[:1], nil
 	}
 
-	return base64Images, nil
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temporary directory: %w", err)
+	}
+
+	var imagePaths []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasPrefix(f.Name(), "page-") {
+			imagePaths = append(imagePaths, filepath.Join(dir, f.Name()))
+		}
+	}
+
+	// Sort the image paths to ensure correct page order
+	// (This is important because ReadDir doesn't guarantee order)
+	sort.Slice(imagePaths, func(i, j int) bool {
+		return pageIndex(imagePaths[i]) < pageIndex(imagePaths[j])
+	})
+
+	return imagePaths, nil
+}
+
+// resizeArgs fits each page into the bounding box and caps its pixel area
+func resizeArgs(options usecase.ImageConvertOptions) []string {
+	var args []string
+
+	if options.Width > 0 || options.Height > 0 {
+		var geometry string
+		if options.Width > 0 {
+			geometry = strconv.Itoa(options.Width)
+		}
+		if options.Height > 0 {
+			geometry += "x" + strconv.Itoa(options.Height)
+		}
+		args = append(args, "-resize", geometry)
+	}
+
+	if options.MaxPixels > 0 {
+		args = append(args, "-resize", fmt.Sprintf("%d@>", options.MaxPixels))
+	}
+
+	return args
+}
+
+type imageSize struct {
+	width  int
+	height int
+}
+
+// imageSizes reads the width and height of each image using ImageMagick's identify
+func (s *ImageMagickConvertService) imageSizes(ctx context.Context, imagePaths []string) ([]imageSize, error) {
+	args := append(s.limits.imageMagickArgs(), "-format", "%w %h\n")
+	args = append(args, imagePaths...)
+	cmd, err := s.toolchain.imageMagickCommand(ctx, "identify", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(ctx, "imagemagick identify", cmd, s.limits, attribute.Int("pdf64.image_count", len(imagePaths))); err != nil {
+		s.metrics.ObserveSubprocessFailure("identify")
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+		return nil, fmt.Errorf("failed to identify image sizes: %w", err)
+	}
+
+	fields := strings.Fields(stdout.String())
+	if len(fields) != len(imagePaths)*2 {
+		return nil, fmt.Errorf("failed to identify image sizes: unexpected output %q", stdout.String())
+	}
+
+	sizes := make([]imageSize, len(imagePaths))
+	for i := range sizes {
+		width, err := strconv.Atoi(fields[i*2])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse image width: %w", err)
+		}
+
+		height, err := strconv.Atoi(fields[i*2+1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse image height: %w", err)
+		}
+
+		sizes[i] = imageSize{width: width, height: height}
+	}
+
+	return sizes, nil
+}
+
+// pageSelectedPath appends ImageMagick's zero-based frame selection to the path
+func pageSelectedPath(path string, pages []int) string {
+	if len(pages) == 0 {
+		return path
+	}
+
+	indexes := make([]string, 0, len(pages))
+	for _, page := range pages {
+		indexes = append(indexes, strconv.Itoa(page-1))
+	}
+
+	return fmt.Sprintf("%s[%s]", path, strings.Join(indexes, ","))
+}
+
+// imagePage resolves the page number of the image at the index in the output
+func imagePage(index int, options usecase.ImageConvertOptions) int {
+	if options.Merge {
+		if len(options.Pages) == 1 {
+			return options.Pages[0]
+		}
+		return 0
+	}
+
+	if len(options.Pages) == 0 {
+		return index + 1
+	}
+
+	if index >= len(options.Pages) {
+		return 0
+	}
+
+	return options.Pages[index]
+}
+
+// pageIndex extracts the scene number from a page-%d output file name
+func pageIndex(path string) int {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	index, err := strconv.Atoi(strings.TrimPrefix(name, "page-"))
+	if err != nil {
+		return -1
+	}
+	return index
 }
@@ -2,108 +2,191 @@ package service_test
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"os"
 	"path/filepath"
-	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
 	"github.com/elct9620/pdf64/internal/service"
 	"github.com/elct9620/pdf64/internal/usecase"
 )
 
 func TestImageMagickConvertService_Convert(t *testing.T) {
-	// Use the real PDF file from fixtures
-	// Get the absolute path to the fixtures directory from project root
-	// First find the project root directory
-	_, currentFile, _, _ := runtime.Caller(0)
-	projectRoot := filepath.Dir(filepath.Dir(filepath.Dir(currentFile)))
-	pdfPath := filepath.Join(projectRoot, "fixtures", "dummy.pdf")
-
-	// Verify the test PDF file exists
-	if _, err := os.Stat(pdfPath); os.IsNotExist(err) {
-		t.Fatalf("Test PDF file not found at %s", pdfPath)
-	}
-
-	// Create file entity
-	file := entity.NewFile("test-id", pdfPath)
-
-	// Create service
-	service := service.NewImageMagickConvertService()
+	convertService := service.NewImageMagickConvertService(service.DefaultToolchain(), "", service.ResourceLimits{}, metrics.New())
+	testImageConvertServiceContract(t, convertService)
+}
 
-	// Test cases for different conversion options
-	testCases := []struct {
-		name    string
-		options usecase.ImageConvertOptions
+// fakeParallelMagick identifies every image as 10x20 and renders each page
+// after a delay, rendering the page in failingPage fails
+const fakeParallelMagick = `
+if [ "$1" = "identify" ]; then
+	for arg in "$@"; do
+		case "$arg" in *.png) echo "10 20" ;; esac
+	done
+	exit 0
+fi
+for output; do :; done
+echo "$@" >> "$(dirname "$0")/calls"
+sleep 0.5
+case "$*" in *"[%s]"*) echo "**** Error: page tree is broken" >&2; exit 1 ;; esac
+touch "$output"
+`
+
+func TestImageMagickConvertService_ParallelPages(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         []int
+		merge         bool
+		failingPage   string
+		expectedCalls int
+		expectedPages []int
+		expectedError error
 	}{
 		{
-			name: "Standard conversion",
-			options: usecase.ImageConvertOptions{
-				Density: "150",
-				Quality: 90,
-				Merge:   false,
-			},
+			name:          "Pages Rendered Concurrently Test",
+			pages:         []int{1, 2, 5, 8},
+			expectedCalls: 4,
+			expectedPages: []int{1, 2, 5, 8},
 		},
 		{
-			name: "Merged conversion",
-			options: usecase.ImageConvertOptions{
-				Density: "150",
-				Quality: 90,
-				Merge:   true,
-			},
+			name:          "Merged Pages Rendered Once Test",
+			pages:         []int{1, 2},
+			merge:         true,
+			expectedCalls: 1,
+			expectedPages: []int{0},
+		},
+		{
+			name:          "Failing Page Test",
+			pages:         []int{1, 2, 3},
+			failingPage:   "1",
+			expectedError: usecase.ErrCorruptDocument,
 		},
 	}
 
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			runConversionTest(t, file, service, tc.options)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failingPage := tt.failingPage
+			if failingPage == "" {
+				failingPage = "none"
+			}
+
+			toolchain, dir := writeFakeMagick(t, strings.Replace(fakeParallelMagick, "%s", failingPage, 1))
+			convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{Processes: 4}, metrics.New())
+
+			startedAt := time.Now()
+			images, err := convertService.Convert(context.Background(), entity.NewFile("test", filepath.Join(dir, "test.pdf")), usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Pages:   tt.pages,
+				Merge:   tt.merge,
+				Format:  usecase.ImageFormatPNG,
+			})
+			elapsed := time.Since(startedAt)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if elapsed > 1500*time.Millisecond {
+				t.Errorf("expected pages to be rendered concurrently, took %s", elapsed)
+			}
+
+			calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count := strings.Count(string(calls), "\n"); count != tt.expectedCalls {
+				t.Errorf("expected %d convert calls, got %d: %s", tt.expectedCalls, count, calls)
+			}
+
+			pages := make([]int, 0, len(images))
+			for _, image := range images {
+				pages = append(pages, image.Page)
+			}
+			if !slices.Equal(pages, tt.expectedPages) {
+				t.Errorf("expected pages %v, got %v", tt.expectedPages, pages)
+			}
 		})
 	}
 }
 
-func runConversionTest(t *testing.T, file *entity.File, service *service.ImageMagickConvertService, options usecase.ImageConvertOptions) {
+func TestImageMagickConvertService_SharedProcesses(t *testing.T) {
+	toolchain, dir := writeFakeMagick(t, strings.Replace(fakeParallelMagick, "%s", "none", 1))
+	convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{Processes: 2}, metrics.New())
 
-	// Convert the PDF to images - this returns base64 encoded images
-	base64Images, err := service.Convert(context.Background(), file, options)
-	if err != nil {
-		t.Fatalf("Failed to convert PDF to images: %v", err)
+	// Two conversions of two pages need four commands, only two run at once
+	startedAt := time.Now()
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := convertService.Convert(context.Background(), entity.NewFile("test", filepath.Join(dir, "test.pdf")), usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+				Pages:   []int{1, 2},
+				Format:  usecase.ImageFormatPNG,
+			})
+			errs <- err
+		}()
 	}
 
-	// Verify we got encoded images
-	if len(base64Images) == 0 {
-		t.Error("Expected at least one encoded image, got none")
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
 	}
 
-	// If merge is enabled, we should only get one image
-	if options.Merge && len(base64Images) > 1 {
-		t.Errorf("Expected only one merged image, got %d", len(base64Images))
+	if elapsed := time.Since(startedAt); elapsed < time.Second {
+		t.Errorf("expected the conversions to share two processes, took %s", elapsed)
 	}
+}
 
-	// Check that the returned strings are valid base64 encoded data
-	for i, encodedImage := range base64Images {
-		if len(encodedImage) == 0 {
-			t.Errorf("Encoded image %d is empty", i)
-			continue
-		}
-
-		// Check if the string starts with the base64 image prefix
-		if !strings.HasPrefix(encodedImage, "data:image/jpeg;base64,") {
-			t.Errorf("Encoded image %d does not have valid image data prefix", i)
-			continue
-		}
-
-		// Extract the base64 part
-		base64Data := strings.TrimPrefix(encodedImage, "data:image/jpeg;base64,")
-
-		// Try to decode it to verify it's valid base64
-		_, err := base64.StdEncoding.DecodeString(base64Data)
-		if err != nil {
-			t.Errorf("Encoded image %d contains invalid base64 data: %v", i, err)
-			continue
-		}
+func TestImageMagickConvertService_OutputLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		pages         []int
+		expectedError error
+	}{
+		{
+			name:  "Within Limit Test",
+			pages: []int{1},
+		},
+		{
+			name:          "Exceeds Limit Test",
+			pages:         []int{1, 2},
+			expectedError: usecase.ErrOutputTooLarge,
+		},
+	}
 
-		t.Logf("Successfully verified base64 encoded image %d", i)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every page is rendered as a 10 bytes image
+			script := strings.Replace(fakeParallelMagick, "%s", "none", 1)
+			script = strings.Replace(script, `touch "$output"`, `printf 0123456789 > "$output"`, 1)
+			toolchain, dir := writeFakeMagick(t, script)
+			convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{Processes: 2}, metrics.New())
+
+			_, err := convertService.Convert(context.Background(), entity.NewFile("test", filepath.Join(dir, "test.pdf")), usecase.ImageConvertOptions{
+				Density:        "150",
+				Quality:        90,
+				Pages:          tt.pages,
+				Format:         usecase.ImageFormatPNG,
+				MaxOutputBytes: 15,
+			})
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
 	}
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var (
+	_ usecase.ImageConvertService = &LimitedImageConvertService{}
+	_ usecase.ConvertAdmission    = &LimitedImageConvertService{}
+)
+
+// admittedKey marks a context admitted by a LimitedImageConvertService
+type admittedKey struct{}
+
+// LimitedImageConvertService bounds the concurrent conversions of the
+// wrapped service, conversions exceeding the limit wait for a slot in a
+// bounded queue and are rejected with usecase.ErrServerBusy when the queue
+// is full or the wait times out
+type LimitedImageConvertService struct {
+	next         usecase.ImageConvertService
+	slots        chan struct{}
+	waiting      atomic.Int64
+	queueSize    int
+	queueTimeout time.Duration
+	retryAfter   time.Duration
+}
+
+// LimitedImageConvertOptions configures the admission of conversions,
+// RetryAfter is suggested to the rejected clients
+type LimitedImageConvertOptions struct {
+	Concurrency  int
+	QueueSize    int
+	QueueTimeout time.Duration
+	RetryAfter   time.Duration
+}
+
+func NewLimitedImageConvertService(next usecase.ImageConvertService, options LimitedImageConvertOptions) *LimitedImageConvertService {
+	return &LimitedImageConvertService{
+		next:         next,
+		slots:        make(chan struct{}, options.Concurrency),
+		queueSize:    options.QueueSize,
+		queueTimeout: options.QueueTimeout,
+		retryAfter:   options.RetryAfter,
+	}
+}
+
+func (s *LimitedImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	ctx, release, err := s.Admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.next.Convert(ctx, file, options)
+}
+
+// Admit holds a slot until release is called, the work done with the
+// returned context uses this slot instead of waiting for another one
+func (s *LimitedImageConvertService) Admit(ctx context.Context) (context.Context, func(), error) {
+	if ctx.Value(admittedKey{}) == s {
+		return ctx, func() {}, nil
+	}
+
+	if err := s.acquire(ctx); err != nil {
+		return ctx, nil, err
+	}
+
+	return context.WithValue(ctx, admittedKey{}, s), s.release, nil
+}
+
+// Len returns the conversions running or waiting for a slot
+func (s *LimitedImageConvertService) Len() int {
+	return len(s.slots) + int(s.waiting.Load())
+}
+
+// Cap returns the conversions which can be admitted at once
+func (s *LimitedImageConvertService) Cap() int {
+	return cap(s.slots) + s.queueSize
+}
+
+func (s *LimitedImageConvertService) acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if s.waiting.Add(1) > int64(s.queueSize) {
+		s.waiting.Add(-1)
+		return s.busyError()
+	}
+	defer s.waiting.Add(-1)
+
+	timer := time.NewTimer(s.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return s.busyError()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *LimitedImageConvertService) release() {
+	<-s.slots
+}
+
+func (s *LimitedImageConvertService) busyError() error {
+	return &usecase.RetryableError{
+		Err:        usecase.ErrServerBusy,
+		RetryAfter: s.retryAfter,
+	}
+}
@@ -0,0 +1,145 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+type blockingImageConvertService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return []usecase.Image{{Page: 1}}, nil
+}
+
+func TestLimitedImageConvertService_Convert(t *testing.T) {
+	tests := []struct {
+		name          string
+		queueSize     int
+		queueTimeout  time.Duration
+		isCancelled   bool
+		expectedError error
+	}{
+		{
+			name:          "Rejected When Queue Is Full Test",
+			queueSize:     0,
+			queueTimeout:  time.Minute,
+			expectedError: usecase.ErrServerBusy,
+		},
+		{
+			name:          "Rejected After Queue Timeout Test",
+			queueSize:     1,
+			queueTimeout:  10 * time.Millisecond,
+			expectedError: usecase.ErrServerBusy,
+		},
+		{
+			name:          "Cancelled While Waiting Test",
+			queueSize:     1,
+			queueTimeout:  time.Minute,
+			isCancelled:   true,
+			expectedError: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &blockingImageConvertService{
+				started: make(chan struct{}, 1),
+				release: make(chan struct{}),
+			}
+			limited := service.NewLimitedImageConvertService(next, service.LimitedImageConvertOptions{
+				Concurrency:  1,
+				QueueSize:    tt.queueSize,
+				QueueTimeout: tt.queueTimeout,
+				RetryAfter:   3 * time.Second,
+			})
+			file := entity.NewFile("test", "test.pdf")
+
+			running := make(chan error, 1)
+			go func() {
+				_, err := limited.Convert(context.Background(), file, usecase.ImageConvertOptions{})
+				running <- err
+			}()
+			<-next.started
+
+			if limited.Len() != 1 || limited.Cap() != 1+tt.queueSize {
+				t.Errorf("expected 1/%d admitted, got %d/%d", 1+tt.queueSize, limited.Len(), limited.Cap())
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.isCancelled {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+			defer cancel()
+
+			_, err := limited.Convert(ctx, file, usecase.ImageConvertOptions{})
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			var retryableErr *usecase.RetryableError
+			if errors.Is(err, usecase.ErrServerBusy) && (!errors.As(err, &retryableErr) || retryableErr.RetryAfter != 3*time.Second) {
+				t.Errorf("expected retry after 3s, got %v", err)
+			}
+
+			close(next.release)
+			if err := <-running; err != nil {
+				t.Fatalf("expected the running conversion to succeed, got %v", err)
+			}
+
+			if limited.Len() != 0 {
+				t.Errorf("expected no admitted conversions, got %d", limited.Len())
+			}
+		})
+	}
+}
+
+func TestLimitedImageConvertService_WaitsForSlot(t *testing.T) {
+	next := &blockingImageConvertService{
+		started: make(chan struct{}, 2),
+		release: make(chan struct{}),
+	}
+	limited := service.NewLimitedImageConvertService(next, service.LimitedImageConvertOptions{
+		Concurrency:  1,
+		QueueSize:    1,
+		QueueTimeout: time.Minute,
+		RetryAfter:   time.Second,
+	})
+	file := entity.NewFile("test", "test.pdf")
+
+	results := make(chan error, 2)
+	for range 2 {
+		go func() {
+			_, err := limited.Convert(context.Background(), file, usecase.ImageConvertOptions{})
+			results <- err
+		}()
+	}
+
+	<-next.started
+	select {
+	case <-next.started:
+		t.Fatal("expected the second conversion to wait for a slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if limited.Len() != 2 {
+		t.Errorf("expected 2 admitted conversions, got %d", limited.Len())
+	}
+
+	close(next.release)
+	for range 2 {
+		if err := <-results; err != nil {
+			t.Errorf("expected the conversions to succeed, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.PdfInfoService = &LimitedPdfInfoService{}
+
+// LimitedPdfInfoService admits document info through the slots and queue of
+// the conversions, reading the structure of a document is as costly as
+// rendering it
+type LimitedPdfInfoService struct {
+	next    usecase.PdfInfoService
+	limiter *LimitedImageConvertService
+}
+
+func NewLimitedPdfInfoService(next usecase.PdfInfoService, limiter *LimitedImageConvertService) *LimitedPdfInfoService {
+	return &LimitedPdfInfoService{
+		next:    next,
+		limiter: limiter,
+	}
+}
+
+func (s *LimitedPdfInfoService) Info(ctx context.Context, file *entity.File) (*usecase.DocumentInfo, error) {
+	ctx, release, err := s.limiter.Admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.next.Info(ctx, file)
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+type stubPdfInfoService struct{}
+
+func (s *stubPdfInfoService) Info(ctx context.Context, file *entity.File) (*usecase.DocumentInfo, error) {
+	return &usecase.DocumentInfo{PdfVersion: "1.7"}, nil
+}
+
+func TestLimitedPdfInfoService_SharesConversionSlots(t *testing.T) {
+	next := &blockingImageConvertService{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	limiter := service.NewLimitedImageConvertService(next, service.LimitedImageConvertOptions{
+		Concurrency:  1,
+		QueueTimeout: time.Minute,
+		RetryAfter:   time.Second,
+	})
+	limited := service.NewLimitedPdfInfoService(&stubPdfInfoService{}, limiter)
+	file := entity.NewFile("test", "test.pdf")
+
+	running := make(chan error, 1)
+	go func() {
+		_, err := limiter.Convert(context.Background(), file, usecase.ImageConvertOptions{})
+		running <- err
+	}()
+	<-next.started
+
+	if _, err := limited.Info(context.Background(), file); !errors.Is(err, usecase.ErrServerBusy) {
+		t.Fatalf("expected ErrServerBusy, got %v", err)
+	}
+
+	close(next.release)
+	if err := <-running; err != nil {
+		t.Fatalf("expected the running conversion to succeed, got %v", err)
+	}
+
+	info, err := limited.Info(context.Background(), file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if info.PdfVersion != "1.7" || limiter.Len() != 0 {
+		t.Errorf("expected info with the slot released, got %+v and %d admitted", info, limiter.Len())
+	}
+}
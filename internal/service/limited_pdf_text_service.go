@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.PdfTextService = &LimitedPdfTextService{}
+
+// LimitedPdfTextService admits text extraction through the slots and queue
+// of the conversions, text attached to a conversion reuses its slot
+type LimitedPdfTextService struct {
+	next    usecase.PdfTextService
+	limiter *LimitedImageConvertService
+}
+
+func NewLimitedPdfTextService(next usecase.PdfTextService, limiter *LimitedImageConvertService) *LimitedPdfTextService {
+	return &LimitedPdfTextService{
+		next:    next,
+		limiter: limiter,
+	}
+}
+
+func (s *LimitedPdfTextService) Text(ctx context.Context, file *entity.File, pages []int) ([]usecase.PageText, error) {
+	ctx, release, err := s.limiter.Admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.next.Text(ctx, file, pages)
+}
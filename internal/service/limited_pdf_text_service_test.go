@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+type stubPdfTextService struct{}
+
+func (s *stubPdfTextService) Text(ctx context.Context, file *entity.File, pages []int) ([]usecase.PageText, error) {
+	return []usecase.PageText{{Page: 1, Text: "text"}}, nil
+}
+
+func TestLimitedPdfTextService_SharesConversionSlots(t *testing.T) {
+	limiter := service.NewLimitedImageConvertService(&blockingImageConvertService{}, service.LimitedImageConvertOptions{
+		Concurrency:  1,
+		QueueTimeout: time.Minute,
+		RetryAfter:   time.Second,
+	})
+	limited := service.NewLimitedPdfTextService(&stubPdfTextService{}, limiter)
+	file := entity.NewFile("test", "test.pdf")
+
+	admittedCtx, release, err := limiter.Admit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := limited.Text(context.Background(), file, nil); !errors.Is(err, usecase.ErrServerBusy) {
+		t.Fatalf("expected ErrServerBusy, got %v", err)
+	}
+
+	if _, err := limited.Text(admittedCtx, file, nil); err != nil {
+		t.Fatalf("expected the admitted conversion to reuse its slot, got %v", err)
+	}
+
+	release()
+	if limiter.Len() != 0 {
+		t.Errorf("expected the slot to be released, got %d admitted", limiter.Len())
+	}
+}
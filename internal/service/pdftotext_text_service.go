@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ usecase.PdfTextService = &PdftotextTextService{}
+
+// PdftotextTextService implements the usecase.PdfTextService interface using
+// Poppler's pdftotext in layout mode
+type PdftotextTextService struct {
+	toolchain Toolchain
+	limits    ResourceLimits
+	metrics   usecase.ConvertMetrics
+}
+
+// NewPdftotextTextService applies the timeout and process limits to each
+// extraction
+func NewPdftotextTextService(toolchain Toolchain, limits ResourceLimits, metrics usecase.ConvertMetrics) *PdftotextTextService {
+	return &PdftotextTextService{
+		toolchain: toolchain,
+		limits:    limits,
+		metrics:   metrics,
+	}
+}
+
+// Text extracts each run of consecutive pages with a single pdftotext
+// invocation, an empty pages list extracts every page
+func (s *PdftotextTextService) Text(ctx context.Context, file *entity.File, pages []int) ([]usecase.PageText, error) {
+	limitCtx, cancel := s.limits.withTimeout(ctx)
+	defer cancel()
+
+	if len(pages) == 0 {
+		return s.extract(ctx, limitCtx, file, 1, 0)
+	}
+
+	texts := make([]usecase.PageText, 0, len(pages))
+	for _, run := range pageRuns(pages) {
+		runTexts, err := s.extract(ctx, limitCtx, file, run[0], run[1])
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, runTexts...)
+	}
+
+	return texts, nil
+}
+
+// extract runs pdftotext from the first to the last page, a zero last page
+// extracts until the end of the document
+func (s *PdftotextTextService) extract(ctx, limitCtx context.Context, file *entity.File, first, last int) ([]usecase.PageText, error) {
+	args := []string{"-layout", "-enc", "UTF-8", "-f", strconv.Itoa(first)}
+	if last > 0 {
+		args = append(args, "-l", strconv.Itoa(last))
+	}
+	args = append(args, file.Path(), "-")
+
+	cmd := s.toolchain.pdftotextCommand(limitCtx, args...)
+
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := runCommand(ctx, "pdftotext extract", cmd, s.limits,
+		attribute.Int("pdf64.first_page", first),
+		attribute.Int("pdf64.last_page", last),
+	)
+	if err != nil {
+		s.metrics.ObserveSubprocessFailure("pdftotext")
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if convErr := conversionError(ctx, limitCtx, s.limits, err, stderr.String(), pdftoppmErrorMessages); convErr != nil {
+			return nil, convErr
+		}
+
+		return nil, fmt.Errorf("failed to extract text with pdftotext: %w", err)
+	}
+
+	// Every page is terminated by a form feed
+	contents := strings.Split(stdout.String(), "\f")
+	contents = contents[:len(contents)-1]
+
+	count := len(contents)
+	if last > 0 {
+		count = last - first + 1
+	}
+
+	texts := make([]usecase.PageText, 0, count)
+	for i := range count {
+		text := usecase.PageText{Page: first + i}
+		if i < len(contents) {
+			text.Text = contents[i]
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, nil
+}
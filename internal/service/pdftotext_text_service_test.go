@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// fakePdftotext prints "text N" for each page from -f to -l followed by a
+// form feed and records every invocation
+const fakePdftotext = `
+echo "$@" >> "$(dirname "$0")/calls"
+i=$5
+while [ "$i" -le "$7" ]; do
+	printf "text %d\f" "$i"
+	i=$((i + 1))
+done
+`
+
+func TestPdftotextTextService_Text(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        string
+		pages         []int
+		expectedCalls []string
+		expectedTexts []usecase.PageText
+		expectedError error
+	}{
+		{
+			name:          "Consecutive Pages Test",
+			script:        fakePdftotext,
+			pages:         []int{1, 2, 3},
+			expectedCalls: []string{"-layout -enc UTF-8 -f 1 -l 3"},
+			expectedTexts: []usecase.PageText{{Page: 1, Text: "text 1"}, {Page: 2, Text: "text 2"}, {Page: 3, Text: "text 3"}},
+		},
+		{
+			name:          "Page Runs Test",
+			script:        fakePdftotext,
+			pages:         []int{2, 3, 7},
+			expectedCalls: []string{"-layout -enc UTF-8 -f 2 -l 3", "-layout -enc UTF-8 -f 7 -l 7"},
+			expectedTexts: []usecase.PageText{{Page: 2, Text: "text 2"}, {Page: 3, Text: "text 3"}, {Page: 7, Text: "text 7"}},
+		},
+		{
+			name:          "Page Without Text Test",
+			script:        `echo "$@" >> "$(dirname "$0")/calls"; printf "\f\f"`,
+			pages:         []int{1, 2},
+			expectedCalls: []string{"-layout -enc UTF-8 -f 1 -l 2"},
+			expectedTexts: []usecase.PageText{{Page: 1}, {Page: 2}},
+		},
+		{
+			name:          "Corrupt Document Test",
+			script:        `echo "Syntax Error: Could not find trailer dictionary" >&2; exit 1`,
+			pages:         []int{1},
+			expectedError: usecase.ErrCorruptDocument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, dir := writeFakeCommand(t, tt.script)
+			toolchain := service.DefaultToolchain()
+			toolchain.Pdftotext = path
+
+			textService := service.NewPdftotextTextService(toolchain, service.ResourceLimits{}, metrics.New())
+			texts, err := textService.Text(context.Background(), entity.NewFile("test", "test.pdf"), tt.pages)
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(texts, tt.expectedTexts) {
+				t.Errorf("expected texts %v, got %v", tt.expectedTexts, texts)
+			}
+
+			calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(string(calls)), "\n")
+			if len(lines) != len(tt.expectedCalls) {
+				t.Fatalf("expected %d invocations, got %q", len(tt.expectedCalls), lines)
+			}
+			for i, line := range lines {
+				if !strings.HasPrefix(line, tt.expectedCalls[i]+" test.pdf -") {
+					t.Errorf("expected invocation %q, got %q", tt.expectedCalls[i], line)
+				}
+			}
+		})
+	}
+}
@@ -4,43 +4,69 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // QpdfDecryptService implements the usecase.PdfDecryptService interface
-type QpdfDecryptService struct{}
+type QpdfDecryptService struct {
+	toolchain Toolchain
+	metrics   usecase.ConvertMetrics
+}
 
 // NewQpdfDecryptService creates a new QpdfDecryptService instance
-func NewQpdfDecryptService() *QpdfDecryptService {
-	return &QpdfDecryptService{}
+func NewQpdfDecryptService(toolchain Toolchain, metrics usecase.ConvertMetrics) *QpdfDecryptService {
+	return &QpdfDecryptService{
+		toolchain: toolchain,
+		metrics:   metrics,
+	}
 }
 
 // Decrypt decrypts a PDF file using qpdf
-func (s *QpdfDecryptService) Decrypt(ctx context.Context, file *entity.File, password string) error {
-	// Create a temporary file for the decrypted output
-	tmpDir := os.TempDir()
-	decryptedPath := filepath.Join(tmpDir, fmt.Sprintf("decrypted-%s.pdf", file.Id()))
+func (s *QpdfDecryptService) Decrypt(ctx context.Context, file *entity.File, password string) (err error) {
+	ctx, span := tracer.Start(ctx, "QpdfDecryptService.Decrypt")
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	// Write the decrypted output next to the original to allow an atomic rename
+	decryptedPath := filepath.Join(filepath.Dir(file.Path()), fmt.Sprintf("decrypted-%s.pdf", file.Id()))
 
 	// Run qpdf to decrypt the file
-	cmd := exec.CommandContext(
+	cmd := s.toolchain.qpdfCommand(
 		ctx,
-		"qpdf",
 		"--decrypt",
 		"--password="+password,
 		file.Path(),
 		decryptedPath,
 	)
 
-	// Capture stderr for better error messages
+	// Capture stderr to tell the password and document errors apart
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to decrypt PDF: %w, stderr: %s", err, stderr.String())
+	startedAt := time.Now()
+	err = runCommand(ctx, "qpdf decrypt", cmd, ResourceLimits{})
+	s.metrics.ObservePhase(usecase.ConvertPhaseDecrypt, time.Since(startedAt))
+	if err != nil && !isQpdfWarning(err) {
+		s.metrics.ObserveSubprocessFailure("qpdf")
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if docErr := documentError(stderr.String(), qpdfErrorMessages); docErr != nil {
+			return docErr
+		}
+
+		return fmt.Errorf("failed to decrypt PDF: %w", err)
 	}
 
 	// Replace the original file with the decrypted one
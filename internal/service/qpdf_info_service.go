@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+)
+
+// maxInheritanceDepth bounds the walk up the page tree for inherited page
+// attributes, which also stops reference cycles
+const maxInheritanceDepth = 32
+
+// DefaultMaxQpdfJSONBytes bounds the JSON read from qpdf, every object of the
+// document is dumped and a hostile document could otherwise exhaust memory
+const DefaultMaxQpdfJSONBytes = 64 << 20
+
+var errQpdfJSONTooLarge = errors.New("qpdf JSON is too large")
+
+var _ usecase.PdfInfoService = &QpdfInfoService{}
+
+// QpdfInfoService implements the usecase.PdfInfoService interface by reading
+// the JSON representation of the document produced by qpdf
+type QpdfInfoService struct {
+	toolchain    Toolchain
+	limits       ResourceLimits
+	maxJSONBytes int64
+}
+
+// NewQpdfInfoService applies the timeout and process limits to each qpdf run
+// and stops it once its output exceeds maxJSONBytes
+func NewQpdfInfoService(toolchain Toolchain, limits ResourceLimits, maxJSONBytes int64) *QpdfInfoService {
+	return &QpdfInfoService{
+		toolchain:    toolchain,
+		limits:       limits,
+		maxJSONBytes: maxJSONBytes,
+	}
+}
+
+// qpdfJSON is the subset of qpdf's JSON version 2 output being used, Qpdf
+// holds the header followed by the objects keyed by "obj:N G R" and "trailer"
+type qpdfJSON struct {
+	Pages []struct {
+		Object string `json:"object"`
+	} `json:"pages"`
+	Qpdf []json.RawMessage `json:"qpdf"`
+}
+
+type qpdfHeader struct {
+	PdfVersion string `json:"pdfversion"`
+}
+
+// qpdfObject is an object with its value, or its dictionary for streams
+type qpdfObject struct {
+	Value  any `json:"value"`
+	Stream *struct {
+		Dict map[string]any `json:"dict"`
+	} `json:"stream"`
+}
+
+// Info returns the version, metadata and pages of a PDF file using qpdf
+func (s *QpdfInfoService) Info(ctx context.Context, file *entity.File) (*usecase.DocumentInfo, error) {
+	limitCtx, cancel := s.limits.withTimeout(ctx)
+	defer cancel()
+
+	cmd := s.toolchain.qpdfCommand(limitCtx, "--json=2", "--json-key=pages", "--json-key=qpdf", file.Path())
+
+	var stderr bytes.Buffer
+	stdout := &cappedBuffer{max: s.maxJSONBytes, cancel: cancel}
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+
+	err := runCommand(ctx, "qpdf json", cmd, s.limits)
+	if stdout.exceeded && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w: qpdf JSON exceeds %d bytes", usecase.ErrResourceLimitExceeded, s.maxJSONBytes)
+	}
+
+	if err != nil && !isQpdfWarning(err) {
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if convErr := conversionError(ctx, limitCtx, s.limits, err, stderr.String(), qpdfErrorMessages); convErr != nil {
+			return nil, convErr
+		}
+
+		return nil, fmt.Errorf("failed to read PDF structure: %w", err)
+	}
+
+	return parseQpdfJSON(stdout.Bytes())
+}
+
+// cappedBuffer keeps up to max bytes and cancels the command writing to it
+// once more is written, the buffer is not embedded as io.Copy would use its
+// ReadFrom and bypass the cap
+type cappedBuffer struct {
+	buffer   bytes.Buffer
+	max      int64
+	cancel   context.CancelFunc
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if int64(b.buffer.Len())+int64(len(p)) > b.max {
+		b.exceeded = true
+		b.cancel()
+		return 0, errQpdfJSONTooLarge
+	}
+
+	return b.buffer.Write(p)
+}
+
+func (b *cappedBuffer) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+func parseQpdfJSON(data []byte) (*usecase.DocumentInfo, error) {
+	var document qpdfJSON
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse qpdf JSON: %w", err)
+	}
+
+	if len(document.Qpdf) != 2 {
+		return nil, fmt.Errorf("failed to parse qpdf JSON: expected header and objects, got %d entries", len(document.Qpdf))
+	}
+
+	var header qpdfHeader
+	if err := json.Unmarshal(document.Qpdf[0], &header); err != nil {
+		return nil, fmt.Errorf("failed to parse qpdf JSON header: %w", err)
+	}
+
+	var objects map[string]qpdfObject
+	if err := json.Unmarshal(document.Qpdf[1], &objects); err != nil {
+		return nil, fmt.Errorf("failed to parse qpdf JSON objects: %w", err)
+	}
+
+	resolver := qpdfResolver{objects: objects}
+
+	info := &usecase.DocumentInfo{
+		PdfVersion: header.PdfVersion,
+		Metadata:   resolver.metadata(),
+		Pages:      make([]usecase.PageInfo, 0, len(document.Pages)),
+	}
+
+	for i, page := range document.Pages {
+		info.Pages = append(info.Pages, resolver.page(i+1, page.Object))
+	}
+
+	return info, nil
+}
+
+// qpdfResolver follows the indirect references between the objects
+type qpdfResolver struct {
+	objects map[string]qpdfObject
+}
+
+// resolve returns the value of an indirect reference, or the dictionary of
+// a stream, other values are returned unchanged
+func (r qpdfResolver) resolve(value any) any {
+	ref, ok := value.(string)
+	if !ok || !isQpdfReference(ref) {
+		return value
+	}
+
+	object, ok := r.objects["obj:"+ref]
+	if !ok {
+		return nil
+	}
+
+	if object.Stream != nil {
+		return object.Stream.Dict
+	}
+
+	return object.Value
+}
+
+// isQpdfReference matches the "N G R" form of indirect references
+func isQpdfReference(value string) bool {
+	fields := strings.Fields(value)
+	if len(fields) != 3 || fields[2] != "R" {
+		return false
+	}
+
+	for _, field := range fields[:2] {
+		if _, err := strconv.Atoi(field); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r qpdfResolver) dict(value any) map[string]any {
+	dict, _ := r.resolve(value).(map[string]any)
+	return dict
+}
+
+// inherited looks the key up in the page and its ancestors in the page tree
+func (r qpdfResolver) inherited(page map[string]any, key string) any {
+	node := page
+	for range maxInheritanceDepth {
+		if node == nil {
+			return nil
+		}
+
+		if value, ok := node[key]; ok {
+			return r.resolve(value)
+		}
+
+		node = r.dict(node["/Parent"])
+	}
+
+	return nil
+}
+
+func (r qpdfResolver) page(number int, ref string) usecase.PageInfo {
+	page := r.dict(ref)
+	info := usecase.PageInfo{Number: number}
+
+	if rotation, ok := r.inherited(page, "/Rotate").(float64); ok {
+		info.Rotation = ((int(rotation) % 360) + 360) % 360
+	}
+
+	if box, ok := r.inherited(page, "/MediaBox").([]any); ok && len(box) == 4 {
+		var coordinates [4]float64
+		for i, value := range box {
+			coordinates[i], _ = r.resolve(value).(float64)
+		}
+
+		info.Width = math.Abs(coordinates[2] - coordinates[0])
+		info.Height = math.Abs(coordinates[3] - coordinates[1])
+		if info.Rotation%180 == 90 {
+			info.Width, info.Height = info.Height, info.Width
+		}
+	}
+
+	resources := r.dict(r.inherited(page, "/Resources"))
+	info.HasText = r.hasFonts(resources, 0)
+
+	return info
+}
+
+// hasFonts reports whether the resources or the resources of the form
+// XObjects they use contain any font
+func (r qpdfResolver) hasFonts(resources map[string]any, depth int) bool {
+	if resources == nil || depth > maxInheritanceDepth {
+		return false
+	}
+
+	if len(r.dict(resources["/Font"])) > 0 {
+		return true
+	}
+
+	for _, xobject := range r.dict(resources["/XObject"]) {
+		form := r.dict(xobject)
+		if form["/Subtype"] != "/Form" {
+			continue
+		}
+
+		if r.hasFonts(r.dict(form["/Resources"]), depth+1) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r qpdfResolver) metadata() usecase.DocumentMetadata {
+	trailer, _ := r.objects["trailer"].Value.(map[string]any)
+	info := r.dict(trailer["/Info"])
+
+	return usecase.DocumentMetadata{
+		Title:    r.text(info["/Title"]),
+		Author:   r.text(info["/Author"]),
+		Subject:  r.text(info["/Subject"]),
+		Keywords: r.text(info["/Keywords"]),
+		Creator:  r.text(info["/Creator"]),
+		Producer: r.text(info["/Producer"]),
+	}
+}
+
+// text decodes a qpdf string, "u:" prefixes text and "b:" hex encoded bytes
+// which are kept when they are UTF-16 with a byte order mark or valid UTF-8
+func (r qpdfResolver) text(value any) string {
+	encoded, _ := r.resolve(value).(string)
+
+	if text, ok := strings.CutPrefix(encoded, "u:"); ok {
+		return text
+	}
+
+	hexData, ok := strings.CutPrefix(encoded, "b:")
+	if !ok {
+		return ""
+	}
+
+	data, err := hex.DecodeString(hexData)
+	if err != nil {
+		return ""
+	}
+
+	if len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+		units := make([]uint16, 0, len(data)/2)
+		for i := 2; i+1 < len(data); i += 2 {
+			units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	if !utf8.Valid(data) {
+		return ""
+	}
+
+	return string(data)
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// qpdfInfoJSON has a page inheriting its media box and resources from the
+// page tree, a rotated page using a font through a form XObject and an image
+// only page
+const qpdfInfoJSON = `{
+  "version": 2,
+  "pages": [
+    {"object": "3 0 R", "pageposfrom1": 1},
+    {"object": "4 0 R", "pageposfrom1": 2},
+    {"object": "5 0 R", "pageposfrom1": 3}
+  ],
+  "qpdf": [
+    {"jsonversion": 2, "pdfversion": "1.7"},
+    {
+      "obj:1 0 R": {"value": {"/Type": "/Catalog", "/Pages": "2 0 R"}},
+      "obj:2 0 R": {"value": {"/Type": "/Pages", "/Kids": ["3 0 R", "4 0 R", "5 0 R"], "/Count": 3, "/MediaBox": [0, 0, 612, 792], "/Resources": "6 0 R"}},
+      "obj:3 0 R": {"value": {"/Type": "/Page", "/Parent": "2 0 R"}},
+      "obj:4 0 R": {"value": {"/Type": "/Page", "/Parent": "2 0 R", "/Rotate": -270, "/MediaBox": [0, 0, 595.28, 841.89], "/Resources": {"/XObject": {"/Fm1": "8 0 R"}}}},
+      "obj:5 0 R": {"value": {"/Type": "/Page", "/Parent": "2 0 R", "/Resources": {"/XObject": {"/Im1": "9 0 R"}}}},
+      "obj:6 0 R": {"value": {"/Font": {"/F1": "7 0 R"}}},
+      "obj:7 0 R": {"value": {"/Type": "/Font", "/Subtype": "/Type1", "/BaseFont": "/Helvetica"}},
+      "obj:8 0 R": {"stream": {"dict": {"/Type": "/XObject", "/Subtype": "/Form", "/Resources": {"/Font": {"/F1": "7 0 R"}}}}},
+      "obj:9 0 R": {"stream": {"dict": {"/Type": "/XObject", "/Subtype": "/Image"}}},
+      "obj:10 0 R": {"value": {"/Title": "u:Quarterly 1 0 R", "/Author": "b:feff00c90076006100", "/Creator": "u:Writer", "/Producer": "u:LibreOffice 7.6"}},
+      "trailer": {"value": {"/Root": "1 0 R", "/Info": "10 0 R", "/Size": 11}}
+    }
+  ]
+}`
+
+func TestQpdfInfoService_Info(t *testing.T) {
+	dir := t.TempDir()
+	fixturePath := filepath.Join(dir, "info.json")
+	if err := os.WriteFile(fixturePath, []byte(qpdfInfoJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, _ := writeFakeCommand(t, "cat "+fixturePath)
+	toolchain := service.DefaultToolchain()
+	toolchain.Qpdf = path
+
+	info, err := service.NewQpdfInfoService(toolchain, service.ResourceLimits{}, service.DefaultMaxQpdfJSONBytes).Info(context.Background(), entity.NewFile("test", filepath.Join(dir, "test.pdf")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &usecase.DocumentInfo{
+		PdfVersion: "1.7",
+		Metadata: usecase.DocumentMetadata{
+			Title:    "Quarterly 1 0 R",
+			Author:   "Éva",
+			Creator:  "Writer",
+			Producer: "LibreOffice 7.6",
+		},
+		Pages: []usecase.PageInfo{
+			{Number: 1, Width: 612, Height: 792, HasText: true},
+			{Number: 2, Width: 841.89, Height: 595.28, Rotation: 90, HasText: true},
+			{Number: 3, Width: 612, Height: 792},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+}
+
+func TestQpdfInfoService_DocumentErrors(t *testing.T) {
+	path, _ := writeFakeCommand(t, "echo 'test.pdf: file is damaged' >&2; exit 2")
+	toolchain := service.DefaultToolchain()
+	toolchain.Qpdf = path
+
+	_, err := service.NewQpdfInfoService(toolchain, service.ResourceLimits{}, service.DefaultMaxQpdfJSONBytes).Info(context.Background(), entity.NewFile("test", "test.pdf"))
+	if !errors.Is(err, usecase.ErrCorruptDocument) {
+		t.Errorf("expected ErrCorruptDocument, got %v", err)
+	}
+}
+
+func TestQpdfInfoService_OutputLimit(t *testing.T) {
+	path, _ := writeFakeCommand(t, "yes '{}'")
+	toolchain := service.DefaultToolchain()
+	toolchain.Qpdf = path
+
+	_, err := service.NewQpdfInfoService(toolchain, service.ResourceLimits{}, 1024).Info(context.Background(), entity.NewFile("test", "test.pdf"))
+	if !errors.Is(err, usecase.ErrResourceLimitExceeded) {
+		t.Errorf("expected ErrResourceLimitExceeded, got %v", err)
+	}
+}
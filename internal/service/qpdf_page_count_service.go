@@ -0,0 +1,51 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/go-chi/httplog/v2"
+)
+
+// QpdfPageCountService implements the usecase.PdfPageCountService interface
+type QpdfPageCountService struct {
+	toolchain Toolchain
+}
+
+// NewQpdfPageCountService creates a new QpdfPageCountService instance
+func NewQpdfPageCountService(toolchain Toolchain) *QpdfPageCountService {
+	return &QpdfPageCountService{
+		toolchain: toolchain,
+	}
+}
+
+// PageCount returns the number of pages in a PDF file using qpdf
+func (s *QpdfPageCountService) PageCount(ctx context.Context, file *entity.File) (int, error) {
+	cmd := s.toolchain.qpdfCommand(ctx, "--show-npages", file.Path())
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil && !isQpdfWarning(err) {
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if docErr := documentError(stderr.String(), qpdfErrorMessages); docErr != nil {
+			return 0, docErr
+		}
+
+		return 0, fmt.Errorf("failed to count PDF pages: %w", err)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(stdout.String()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse PDF page count: %w", err)
+	}
+
+	return count, nil
+}
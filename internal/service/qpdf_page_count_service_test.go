@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+)
+
+func TestQpdfPageCountService_PageCount(t *testing.T) {
+	// Ensure qpdf is installed
+	if _, err := exec.LookPath("qpdf"); err != nil {
+		t.Fatalf("qpdf is required for testing: %v", err)
+	}
+
+	// Find project root directory to locate fixtures
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	// Go up from internal/service to project root
+	projectRoot := filepath.Join(wd, "..", "..")
+
+	// Path to the fixture PDF
+	fixturesPdfPath := filepath.Join(projectRoot, "fixtures", "dummy.pdf")
+	if _, err := os.Stat(fixturesPdfPath); os.IsNotExist(err) {
+		t.Fatalf("fixture PDF not found at %s: %v", fixturesPdfPath, err)
+	}
+
+	pageCountService := service.NewQpdfPageCountService(service.DefaultToolchain())
+
+	count, err := pageCountService.PageCount(context.Background(), entity.NewFile("test-id", fixturesPdfPath))
+	if err != nil {
+		t.Fatalf("failed to count pages: %v", err)
+	}
+
+	if count < 1 {
+		t.Errorf("expected at least one page, got %d", count)
+	}
+
+	_, err = pageCountService.PageCount(context.Background(), entity.NewFile("test-id", filepath.Join(wd, "missing.pdf")))
+	if err == nil {
+		t.Error("counting pages of a missing file should fail")
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.DependencyCheck = &QueueCheck{}
+
+// QueueCheck reports the queue as not ready once no more tasks can be
+// admitted or a closable queue is closed
+type QueueCheck struct {
+	name  string
+	queue BoundedQueue
+}
+
+// BoundedQueue exposes the occupancy of a queue, admission fails once Len
+// reaches Cap
+type BoundedQueue interface {
+	Len() int
+	Cap() int
+}
+
+type closableQueue interface {
+	IsClosed() bool
+}
+
+func NewQueueCheck(name string, queue BoundedQueue) *QueueCheck {
+	return &QueueCheck{
+		name:  name,
+		queue: queue,
+	}
+}
+
+func (c *QueueCheck) Name() string {
+	return c.name
+}
+
+func (c *QueueCheck) Check(ctx context.Context) (string, error) {
+	if queue, ok := c.queue.(closableQueue); ok && queue.IsClosed() {
+		return "", errors.New("queue is shutting down")
+	}
+
+	length, capacity := c.queue.Len(), c.queue.Cap()
+	if length >= capacity {
+		return "", fmt.Errorf("queue is saturated: %d/%d", length, capacity)
+	}
+
+	return fmt.Sprintf("%d/%d", length, capacity), nil
+}
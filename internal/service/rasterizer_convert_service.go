@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// rasterizer renders the selected pages of a PDF into PNG files named
+// page-<n>.png, the number n sorts the files in page order
+type rasterizer interface {
+	commands(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) []*exec.Cmd
+	errorMessages() []commandErrorMessage
+}
+
+// RasterizerConvertService implements the ImageConvertService interface with
+// a dedicated PDF rasterizer, ImageMagick only encodes the rendered PNG pages
+// when another format, resizing or merging is requested
+type RasterizerConvertService struct {
+	name       string
+	rasterizer rasterizer
+	encoder    *ImageMagickConvertService
+	tempDir    string
+	limits     ResourceLimits
+	metrics    usecase.ConvertMetrics
+}
+
+// NewPdftoppmConvertService renders with Poppler's pdftoppm
+func NewPdftoppmConvertService(toolchain Toolchain, tempDir string, limits ResourceLimits, metrics usecase.ConvertMetrics) *RasterizerConvertService {
+	return newRasterizerConvertService("pdftoppm", pdftoppmRasterizer{toolchain}, toolchain, tempDir, limits, metrics)
+}
+
+// NewMutoolConvertService renders with MuPDF's mutool draw
+func NewMutoolConvertService(toolchain Toolchain, tempDir string, limits ResourceLimits, metrics usecase.ConvertMetrics) *RasterizerConvertService {
+	return newRasterizerConvertService("mutool", mutoolRasterizer{toolchain}, toolchain, tempDir, limits, metrics)
+}
+
+// NewGhostscriptConvertService renders with Ghostscript directly instead of
+// through ImageMagick's delegate
+func NewGhostscriptConvertService(toolchain Toolchain, tempDir string, limits ResourceLimits, metrics usecase.ConvertMetrics) *RasterizerConvertService {
+	return newRasterizerConvertService("gs", ghostscriptRasterizer{toolchain}, toolchain, tempDir, limits, metrics)
+}
+
+func newRasterizerConvertService(name string, rasterizer rasterizer, toolchain Toolchain, tempDir string, limits ResourceLimits, metrics usecase.ConvertMetrics) *RasterizerConvertService {
+	return &RasterizerConvertService{
+		name:       name,
+		rasterizer: rasterizer,
+		encoder:    NewImageMagickConvertService(toolchain, tempDir, limits, metrics),
+		tempDir:    tempDir,
+		limits:     limits,
+		metrics:    metrics,
+	}
+}
+
+// Convert renders the pages as PNG and encodes them when needed. Running the
+// rasterizer is measured as the rasterize phase and the rest as the encode
+// phase.
+func (s *RasterizerConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	tmpDir, err := os.MkdirTemp(s.tempDir, "pdf64-images-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	limitCtx, cancel := s.limits.withTimeout(ctx)
+	defer cancel()
+
+	startedAt := time.Now()
+	err = s.render(ctx, limitCtx, file, options, tmpDir)
+	s.metrics.ObservePhase(usecase.ConvertPhaseRasterize, time.Since(startedAt))
+	if err != nil {
+		return nil, err
+	}
+
+	encodeStartedAt := time.Now()
+	defer func() {
+		s.metrics.ObservePhase(usecase.ConvertPhaseEncode, time.Since(encodeStartedAt))
+	}()
+
+	imagePaths, err := outputPaths(tmpDir, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imagePaths) == 0 {
+		return nil, fmt.Errorf("%s rendered no pages", s.name)
+	}
+
+	if needsEncoding(options) {
+		encodedDir := filepath.Join(tmpDir, "encoded")
+		if err := os.Mkdir(encodedDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+
+		imagePaths, err = s.encoder.encode(limitCtx, imagePaths, options, encodedDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.encoder.readImages(ctx, imagePaths, options)
+}
+
+// render runs the rasterizer commands one after another
+func (s *RasterizerConvertService) render(ctx, limitCtx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) error {
+	for _, cmd := range s.rasterizer.commands(limitCtx, file, options, dir) {
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+
+		err := runCommand(ctx, s.name+" render", cmd, s.limits,
+			attribute.String("pdf64.density", options.Density),
+			attribute.Int("pdf64.page_count", len(options.Pages)),
+		)
+		if err == nil {
+			continue
+		}
+
+		s.metrics.ObserveSubprocessFailure(s.name)
+		httplog.LogEntrySetField(ctx, "stderr", slog.StringValue(stderr.String()))
+
+		if convErr := conversionError(ctx, limitCtx, s.limits, err, stderr.String(), s.rasterizer.errorMessages()); convErr != nil {
+			return convErr
+		}
+
+		return fmt.Errorf("failed to render PDF with %s: %w", s.name, err)
+	}
+
+	return nil
+}
+
+// needsEncoding is false when the rendered PNG pages can be returned as is
+func needsEncoding(options usecase.ImageConvertOptions) bool {
+	isPNG := options.Format == usecase.ImageFormatPNG
+	isResized := options.Width > 0 || options.Height > 0 || options.MaxPixels > 0
+	return !isPNG || options.Merge || isResized
+}
+
+// resolution splits an ImageMagick density like 150 or 150x300 into the
+// horizontal and vertical resolution
+func resolution(density string) (string, string) {
+	x, y, found := strings.Cut(density, "x")
+	if !found {
+		return x, x
+	}
+	return x, y
+}
+
+// pageRuns groups sorted page numbers into runs of consecutive pages
+func pageRuns(pages []int) [][2]int {
+	var runs [][2]int
+	for _, page := range pages {
+		if len(runs) > 0 && runs[len(runs)-1][1] == page-1 {
+			runs[len(runs)-1][1] = page
+			continue
+		}
+		runs = append(runs, [2]int{page, page})
+	}
+	return runs
+}
+
+// pageList formats the pages as a comma separated list with ranges
+func pageList(pages []int) string {
+	runs := pageRuns(pages)
+	segments := make([]string, 0, len(runs))
+	for _, run := range runs {
+		if run[0] == run[1] {
+			segments = append(segments, fmt.Sprintf("%d", run[0]))
+		} else {
+			segments = append(segments, fmt.Sprintf("%d-%d", run[0], run[1]))
+		}
+	}
+	return strings.Join(segments, ",")
+}
+
+// pdftoppmRasterizer names the files after the page numbers and only
+// accepts a single range, each run of pages is rendered separately
+type pdftoppmRasterizer struct {
+	toolchain Toolchain
+}
+
+func (r pdftoppmRasterizer) commands(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) []*exec.Cmd {
+	x, y := resolution(options.Density)
+	args := []string{"-png"}
+	if x != "" {
+		args = append(args, "-rx", x, "-ry", y)
+	}
+
+	prefix := filepath.Join(dir, "page")
+	if len(options.Pages) == 0 {
+		return []*exec.Cmd{r.toolchain.pdftoppmCommand(ctx, append(args, file.Path(), prefix)...)}
+	}
+
+	var cmds []*exec.Cmd
+	for _, run := range pageRuns(options.Pages) {
+		runArgs := append(args[:len(args):len(args)], "-f", fmt.Sprintf("%d", run[0]), "-l", fmt.Sprintf("%d", run[1]), file.Path(), prefix)
+		cmds = append(cmds, r.toolchain.pdftoppmCommand(ctx, runArgs...))
+	}
+	return cmds
+}
+
+func (r pdftoppmRasterizer) errorMessages() []commandErrorMessage {
+	return pdftoppmErrorMessages
+}
+
+// mutoolRasterizer names the files after the page numbers
+type mutoolRasterizer struct {
+	toolchain Toolchain
+}
+
+func (r mutoolRasterizer) commands(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) []*exec.Cmd {
+	args := []string{"draw", "-q"}
+	if x, _ := resolution(options.Density); x != "" {
+		args = append(args, "-r", x)
+	}
+
+	args = append(args, "-o", filepath.Join(dir, "page-%d.png"), file.Path())
+	if len(options.Pages) > 0 {
+		args = append(args, pageList(options.Pages))
+	}
+
+	return []*exec.Cmd{r.toolchain.mutoolCommand(ctx, args...)}
+}
+
+func (r mutoolRasterizer) errorMessages() []commandErrorMessage {
+	return mutoolErrorMessages
+}
+
+// ghostscriptRasterizer numbers the files in output order
+type ghostscriptRasterizer struct {
+	toolchain Toolchain
+}
+
+func (r ghostscriptRasterizer) commands(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions, dir string) []*exec.Cmd {
+	args := []string{
+		"-q", "-dSAFER", "-dBATCH", "-dNOPAUSE",
+		"-sDEVICE=png16m",
+		"-dTextAlphaBits=4", "-dGraphicsAlphaBits=4",
+	}
+
+	if x, y := resolution(options.Density); x != "" {
+		args = append(args, "-r"+x+"x"+y)
+	}
+
+	if len(options.Pages) > 0 {
+		args = append(args, "-sPageList="+pageList(options.Pages))
+	}
+
+	args = append(args, "-sOutputFile="+filepath.Join(dir, "page-%d.png"), file.Path())
+
+	return []*exec.Cmd{r.toolchain.ghostscriptCommand(ctx, args...)}
+}
+
+func (r ghostscriptRasterizer) errorMessages() []commandErrorMessage {
+	return ghostscriptErrorMessages
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestPdftoppmConvertService_Convert(t *testing.T) {
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		t.Fatalf("pdftoppm is required for testing: %v", err)
+	}
+
+	convertService := service.NewPdftoppmConvertService(service.DefaultToolchain(), "", service.ResourceLimits{}, metrics.New())
+	testImageConvertServiceContract(t, convertService)
+}
+
+func TestMutoolConvertService_Convert(t *testing.T) {
+	if _, err := exec.LookPath("mutool"); err != nil {
+		t.Fatalf("mutool is required for testing: %v", err)
+	}
+
+	convertService := service.NewMutoolConvertService(service.DefaultToolchain(), "", service.ResourceLimits{}, metrics.New())
+	testImageConvertServiceContract(t, convertService)
+}
+
+func TestGhostscriptConvertService_Convert(t *testing.T) {
+	if _, err := exec.LookPath("gs"); err != nil {
+		t.Fatalf("gs is required for testing: %v", err)
+	}
+
+	convertService := service.NewGhostscriptConvertService(service.DefaultToolchain(), "", service.ResourceLimits{}, metrics.New())
+	testImageConvertServiceContract(t, convertService)
+}
+
+func TestRasterizerConvertService_Arguments(t *testing.T) {
+	tests := []struct {
+		name         string
+		newService   func(toolchain service.Toolchain) usecase.ImageConvertService
+		setCommand   func(toolchain *service.Toolchain, path string)
+		script       string
+		expectedArgs []string
+		expectedErr  error
+	}{
+		{
+			name: "Pdftoppm Page Runs Test",
+			newService: func(toolchain service.Toolchain) usecase.ImageConvertService {
+				return service.NewPdftoppmConvertService(toolchain, "", service.ResourceLimits{}, metrics.New())
+			},
+			setCommand: func(toolchain *service.Toolchain, path string) { toolchain.Pdftoppm = path },
+			expectedArgs: []string{
+				"-png -rx 150 -ry 300 -f 1 -l 2 test.pdf",
+				"-png -rx 150 -ry 300 -f 5 -l 5 test.pdf",
+			},
+		},
+		{
+			name: "Mutool Page List Test",
+			newService: func(toolchain service.Toolchain) usecase.ImageConvertService {
+				return service.NewMutoolConvertService(toolchain, "", service.ResourceLimits{}, metrics.New())
+			},
+			setCommand:   func(toolchain *service.Toolchain, path string) { toolchain.Mutool = path },
+			expectedArgs: []string{"draw -q -r 150 -o", "test.pdf 1-2,5"},
+		},
+		{
+			name: "Ghostscript Page List Test",
+			newService: func(toolchain service.Toolchain) usecase.ImageConvertService {
+				return service.NewGhostscriptConvertService(toolchain, "", service.ResourceLimits{}, metrics.New())
+			},
+			setCommand:   func(toolchain *service.Toolchain, path string) { toolchain.Ghostscript = path },
+			expectedArgs: []string{"-sDEVICE=png16m -dTextAlphaBits=4 -dGraphicsAlphaBits=4 -r150x300 -sPageList=1-2,5"},
+		},
+		{
+			name: "Pdftoppm Invalid Password Test",
+			newService: func(toolchain service.Toolchain) usecase.ImageConvertService {
+				return service.NewPdftoppmConvertService(toolchain, "", service.ResourceLimits{}, metrics.New())
+			},
+			setCommand:  func(toolchain *service.Toolchain, path string) { toolchain.Pdftoppm = path },
+			script:      "echo 'Command Line Error: Incorrect password' >&2; exit 1",
+			expectedErr: usecase.ErrInvalidPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			argsPath := filepath.Join(dir, "args")
+			script := "echo \"$@\" >> " + argsPath
+			if tt.script != "" {
+				script = tt.script
+			}
+
+			path := filepath.Join(dir, "command")
+			if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			toolchain := service.DefaultToolchain()
+			tt.setCommand(&toolchain, path)
+
+			_, err := tt.newService(toolchain).Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+				Density: "150x300",
+				Quality: 90,
+				Pages:   []int{1, 2, 5},
+			})
+			if err == nil {
+				t.Fatal("expected an error as the fake command renders no pages")
+			}
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			args, err := os.ReadFile(argsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expectedArgs {
+				if !strings.Contains(string(args), expected) {
+					t.Errorf("expected arguments to contain %q, got %q", expected, args)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/usecase"
+	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RendererImageConvertService implements the ImageConvertService interface
+// by dispatching each conversion to the renderer selected in the options
+type RendererImageConvertService struct {
+	defaultRenderer usecase.Renderer
+	renderers       map[usecase.Renderer]usecase.ImageConvertService
+}
+
+// NewRendererImageConvertService uses defaultRenderer for the conversions
+// which do not select a renderer
+func NewRendererImageConvertService(defaultRenderer usecase.Renderer, renderers map[usecase.Renderer]usecase.ImageConvertService) *RendererImageConvertService {
+	return &RendererImageConvertService{
+		defaultRenderer: defaultRenderer,
+		renderers:       renderers,
+	}
+}
+
+// Convert fails with usecase.ErrUnsupportedRenderer when the renderer is not
+// available, the images are marked with the renderer producing them
+func (s *RendererImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	renderer := options.Renderer
+	if renderer == usecase.RendererDefault {
+		renderer = s.defaultRenderer
+	}
+
+	converter, ok := s.renderers[renderer]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", usecase.ErrUnsupportedRenderer, renderer)
+	}
+
+	images, err := converter.Convert(ctx, file, options)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range images {
+		images[i].Renderer = renderer
+	}
+	httplog.LogEntrySetField(ctx, "renderer", slog.StringValue(string(renderer)))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("pdf64.renderer.used", string(renderer)))
+
+	return images, nil
+}
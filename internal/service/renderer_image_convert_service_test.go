@@ -0,0 +1,76 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// namedImageConvertService returns a single image whose MIME type is its name
+type namedImageConvertService struct {
+	name string
+}
+
+func (s *namedImageConvertService) Convert(ctx context.Context, file *entity.File, options usecase.ImageConvertOptions) ([]usecase.Image, error) {
+	return []usecase.Image{{Page: 1, MimeType: s.name}}, nil
+}
+
+func TestRendererImageConvertService_Convert(t *testing.T) {
+	tests := []struct {
+		name          string
+		renderer      usecase.Renderer
+		expectedName  string
+		expectedError error
+	}{
+		{
+			name:         "Default Renderer Test",
+			renderer:     usecase.RendererDefault,
+			expectedName: "imagemagick",
+		},
+		{
+			name:         "Selected Renderer Test",
+			renderer:     usecase.RendererPdftoppm,
+			expectedName: "pdftoppm",
+		},
+		{
+			name:          "Unavailable Renderer Test",
+			renderer:      usecase.RendererMutool,
+			expectedError: usecase.ErrUnsupportedRenderer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := service.NewRendererImageConvertService(usecase.RendererImageMagick, map[usecase.Renderer]usecase.ImageConvertService{
+				usecase.RendererImageMagick: &namedImageConvertService{name: "imagemagick"},
+				usecase.RendererPdftoppm:    &namedImageConvertService{name: "pdftoppm"},
+			})
+
+			images, err := converter.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+				Renderer: tt.renderer,
+			})
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if images[0].MimeType != tt.expectedName {
+				t.Errorf("expected renderer %q to be used, got %q", tt.expectedName, images[0].MimeType)
+			}
+
+			if string(images[0].Renderer) != tt.expectedName {
+				t.Errorf("expected image renderer %q, got %q", tt.expectedName, images[0].Renderer)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// imageMagickLimitMessages maps the errors ImageMagick and Ghostscript report
+// for an exhausted resource to the exceeded limit
+var imageMagickLimitMessages = []struct {
+	message string
+	limit   string
+}{
+	{"cache resources exhausted", "pixel cache"},
+	{"memory allocation failed", "memory"},
+	{"width or height exceeds limit", "image size"},
+	{"VMerror", "memory"},
+}
+
+// ResourceLimits bound a single ImageMagick invocation including the
+// Ghostscript delegate it spawns, zero values leave a resource unlimited
+type ResourceLimits struct {
+	// Memory, Map, Disk and Area are passed to ImageMagick's -limit option
+	// and accept its units, e.g. 256MiB or 128MP for the pixel area
+	Memory string
+	Map    string
+	Disk   string
+	Area   string
+	// Processes bounds the ImageMagick processes running at once across every
+	// conversion, the pages of a conversion are rendered by separate
+	// processes when greater than one
+	Processes int
+	// Timeout kills the process and its children once exceeded
+	Timeout time.Duration
+	// ProcessMemory, ProcessCPUTime and ProcessFileSize are applied as
+	// rlimits on Linux before the process starts, which requires main to
+	// call RunLimitedExecHelper
+	ProcessMemory   uint64
+	ProcessCPUTime  time.Duration
+	ProcessFileSize uint64
+}
+
+// imageMagickArgs returns the -limit options, they must precede the input
+func (l ResourceLimits) imageMagickArgs() []string {
+	var args []string
+
+	for _, limit := range []struct{ name, value string }{
+		{"memory", l.Memory},
+		{"map", l.Map},
+		{"disk", l.Disk},
+		{"area", l.Area},
+	} {
+		if limit.value != "" {
+			args = append(args, "-limit", limit.name, limit.value)
+		}
+	}
+
+	if l.Timeout > 0 {
+		args = append(args, "-limit", "time", strconv.Itoa(int(l.Timeout.Seconds())))
+	}
+
+	return args
+}
+
+// withTimeout derives the context bounding the wall-clock time of a command
+func (l ResourceLimits) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, l.Timeout)
+}
+
+// isTimedOut reports whether the command was stopped by the timeout, either
+// through the context or by ImageMagick's own time limit
+func (l ResourceLimits) isTimedOut(ctx context.Context, stderr string) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) || strings.Contains(stderr, "time limit exceeded")
+}
+
+// exceededLimit describes the limit which made the command fail, it is
+// empty when the failure is unrelated to the limits
+func exceededLimit(err error, stderr string) string {
+	if limit := exceededRlimit(err); limit != "" {
+		return limit
+	}
+
+	for _, entry := range imageMagickLimitMessages {
+		if strings.Contains(stderr, entry.message) {
+			return entry.limit + " limit"
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,113 @@
+//go:build linux
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// prepareProcess starts the command in its own process group which is
+// killed as a whole when the context is done
+func prepareProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+const (
+	// limitedExecArg marks the re-execution of the server as the helper
+	// which applies the rlimits and then replaces itself with the command
+	limitedExecArg = "pdf64-limited-exec"
+	// selfExecutable still refers to the running binary after it is replaced
+	selfExecutable = "/proc/self/exe"
+)
+
+// rlimitResources are the resources of the values returned by rlimits
+var rlimitResources = []int{unix.RLIMIT_AS, unix.RLIMIT_CPU, unix.RLIMIT_FSIZE}
+
+// rlimits returns the limit of each rlimitResources, zero is unlimited
+func (l ResourceLimits) rlimits() []uint64 {
+	return []uint64{l.ProcessMemory, uint64(l.ProcessCPUTime.Seconds()), l.ProcessFileSize}
+}
+
+// limitCommand starts the command through the limited exec helper, the
+// rlimits are therefore set before the command and its children run
+func (l ResourceLimits) limitCommand(cmd *exec.Cmd) {
+	values := l.rlimits()
+	if cmd.Err != nil || !slices.ContainsFunc(values, func(value uint64) bool { return value > 0 }) {
+		return
+	}
+
+	args := []string{selfExecutable, limitedExecArg}
+	for _, value := range values {
+		args = append(args, strconv.FormatUint(value, 10))
+	}
+	args = append(args, cmd.Path)
+
+	cmd.Args = append(args, cmd.Args...)
+	cmd.Path = selfExecutable
+}
+
+// RunLimitedExecHelper applies the rlimits and executes the command when
+// the process is started by limitCommand, otherwise it returns immediately.
+// It must be called at the start of main before any other work.
+func RunLimitedExecHelper() {
+	// The arguments are the marker, the limits, the path and the argv
+	pathIndex := 2 + len(rlimitResources)
+	if len(os.Args) < pathIndex+2 || os.Args[1] != limitedExecArg {
+		return
+	}
+
+	for i, resource := range rlimitResources {
+		value, err := strconv.ParseUint(os.Args[2+i], 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid resource limit %q\n", os.Args[2+i])
+			os.Exit(126)
+		}
+
+		if value == 0 {
+			continue
+		}
+
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: value, Max: value}); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to apply resource limits: %v\n", err)
+			os.Exit(126)
+		}
+	}
+
+	path := os.Args[pathIndex]
+	err := syscall.Exec(path, os.Args[pathIndex+1:], os.Environ())
+	fmt.Fprintf(os.Stderr, "failed to run %s: %v\n", path, err)
+	os.Exit(127)
+}
+
+// exceededRlimit describes the rlimit whose signal terminated the command
+func exceededRlimit(err error) string {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return ""
+	}
+
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+
+	switch status.Signal() {
+	case syscall.SIGXCPU:
+		return "cpu time limit"
+	case syscall.SIGXFSZ:
+		return "file size limit"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package service
+
+import "os/exec"
+
+func prepareProcess(cmd *exec.Cmd) {}
+
+// limitCommand does not support rlimits outside Linux, only the ImageMagick
+// limits and the timeout apply
+func (l ResourceLimits) limitCommand(cmd *exec.Cmd) {}
+
+// RunLimitedExecHelper is only needed on Linux
+func RunLimitedExecHelper() {}
+
+func exceededRlimit(err error) string {
+	return ""
+}
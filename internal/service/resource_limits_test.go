@@ -0,0 +1,177 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"github.com/elct9620/pdf64/internal/metrics"
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestMain(m *testing.M) {
+	// The rlimits are applied by re-executing the test binary
+	service.RunLimitedExecHelper()
+
+	os.Exit(m.Run())
+}
+
+// writeFakeCommand creates an executable running the script, the arguments
+// are recorded into the args file of the returned directory
+func writeFakeCommand(t *testing.T, script string) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "command")
+	content := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\n" + script + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, dir
+}
+
+// writeFakeMagick creates a toolchain whose magick command runs the script
+func writeFakeMagick(t *testing.T, script string) (service.Toolchain, string) {
+	t.Helper()
+
+	path, dir := writeFakeCommand(t, script)
+	toolchain := service.DefaultToolchain()
+	toolchain.Magick = path
+	return toolchain, dir
+}
+
+func TestImageMagickConvertService_ResourceLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      string
+		limits      service.ResourceLimits
+		expectedErr error
+	}{
+		{
+			name:        "Timeout Test",
+			script:      "sleep 5",
+			limits:      service.ResourceLimits{Timeout: 50 * time.Millisecond},
+			expectedErr: usecase.ErrConversionTimeout,
+		},
+		{
+			name:        "ImageMagick Time Limit Test",
+			script:      "echo 'convert: time limit exceeded @ fatal/cache.c/GetImagePixelCache/1868.' >&2; exit 1",
+			expectedErr: usecase.ErrConversionTimeout,
+		},
+		{
+			name:        "Exhausted Pixel Cache Test",
+			script:      "echo 'convert: cache resources exhausted @ error/cache.c/OpenPixelCache/4095.' >&2; exit 1",
+			expectedErr: usecase.ErrResourceLimitExceeded,
+		},
+		{
+			name:   "Unrelated Failure Test",
+			script: "echo 'convert: no images defined' >&2; exit 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolchain, _ := writeFakeMagick(t, tt.script)
+			convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), tt.limits, metrics.New())
+
+			startedAt := time.Now()
+			_, err := convertService.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+				Density: "150",
+				Quality: 90,
+			})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+
+			if tt.expectedErr == nil && (errors.Is(err, usecase.ErrResourceLimitExceeded) || errors.Is(err, usecase.ErrConversionTimeout)) {
+				t.Errorf("expected an unrelated error, got %v", err)
+			}
+
+			if time.Since(startedAt) > 4*time.Second {
+				t.Error("expected the command to be killed after the timeout")
+			}
+		})
+	}
+}
+
+func TestImageMagickConvertService_LimitArguments(t *testing.T) {
+	toolchain, dir := writeFakeMagick(t, "exit 1")
+	convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{
+		Memory:  "256MiB",
+		Disk:    "1GiB",
+		Area:    "64MP",
+		Timeout: time.Minute,
+	}, metrics.New())
+
+	_, _ = convertService.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+		Density: "150",
+		Quality: 90,
+	})
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPrefix := "-limit memory 256MiB -limit disk 1GiB -limit area 64MP -limit time 60 -density 150"
+	if !strings.HasPrefix(string(args), expectedPrefix) {
+		t.Errorf("expected arguments to start with %q, got %q", expectedPrefix, args)
+	}
+}
+
+func TestImageMagickConvertService_ProcessLimits(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("rlimits are only applied on Linux")
+	}
+
+	toolchain, dir := writeFakeMagick(t, "ulimit -f > \"$(dirname \"$0\")/fsize\"; ulimit -v > \"$(dirname \"$0\")/memory\"; exit 1")
+	convertService := service.NewImageMagickConvertService(toolchain, t.TempDir(), service.ResourceLimits{
+		ProcessMemory:   1 << 30,
+		ProcessFileSize: 1 << 20,
+	}, metrics.New())
+
+	_, _ = convertService.Convert(context.Background(), entity.NewFile("test", "test.pdf"), usecase.ImageConvertOptions{
+		Density: "150",
+		Quality: 90,
+	})
+
+	fileSize, err := os.ReadFile(filepath.Join(dir, "fsize"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.TrimSpace(string(fileSize)) == "unlimited" {
+		t.Error("expected the file size rlimit to be applied")
+	}
+
+	memory, err := os.ReadFile(filepath.Join(dir, "memory"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ulimit reports the address space in KiB
+	if strings.TrimSpace(string(memory)) != "1048576" {
+		t.Errorf("expected the memory rlimit to be 1048576 KiB, got %q", memory)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(args), "-density 150") {
+		t.Errorf("expected the command arguments to be unchanged, got %q", args)
+	}
+}
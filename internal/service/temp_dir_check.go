@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+var _ usecase.DependencyCheck = &TempDirCheck{}
+
+// TempDirCheck verifies a file can be created in the temporary directory
+type TempDirCheck struct {
+	dir string
+}
+
+func NewTempDirCheck(dir string) *TempDirCheck {
+	return &TempDirCheck{
+		dir: dir,
+	}
+}
+
+func (c *TempDirCheck) Name() string {
+	return "temp_dir"
+}
+
+func (c *TempDirCheck) Check(ctx context.Context) (string, error) {
+	file, err := os.CreateTemp(c.dir, "readyz-*")
+	if err != nil {
+		return "", fmt.Errorf("temp directory is not writable: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString("ok"); err != nil {
+		file.Close()
+		return "", fmt.Errorf("temp directory is not writable: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("temp directory is not writable: %w", err)
+	}
+
+	return c.dir, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/elct9620/pdf64/internal/service")
+
+// Toolchain locates the external binaries, each entry is either a path or a
+// command name resolved from PATH
+type Toolchain struct {
+	Magick      string
+	Convert     string
+	Identify    string
+	Qpdf        string
+	Ghostscript string
+	Pdftoppm    string
+	Pdftotext   string
+	Mutool      string
+}
+
+// DefaultToolchain resolves every binary from PATH
+func DefaultToolchain() Toolchain {
+	return Toolchain{
+		Magick:      "magick",
+		Convert:     "convert",
+		Identify:    "identify",
+		Qpdf:        "qpdf",
+		Ghostscript: "gs",
+		Pdftoppm:    "pdftoppm",
+		Pdftotext:   "pdftotext",
+		Mutool:      "mutool",
+	}
+}
+
+// imageMagickCommand prepares an ImageMagick tool invocation, preferring the
+// ImageMagick 7 magick command over the legacy standalone tools
+func (t Toolchain) imageMagickCommand(ctx context.Context, tool string, args ...string) (*exec.Cmd, error) {
+	if cliPath, err := exec.LookPath(t.Magick); err == nil {
+		if tool != "convert" {
+			args = append([]string{tool}, args...)
+		}
+		return exec.CommandContext(ctx, cliPath, args...), nil
+	}
+
+	legacyPath := t.Convert
+	if tool == "identify" {
+		legacyPath = t.Identify
+	}
+
+	cliPath, err := exec.LookPath(legacyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ImageMagick command: %w", err)
+	}
+
+	return exec.CommandContext(ctx, cliPath, args...), nil
+}
+
+func (t Toolchain) qpdfCommand(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, t.Qpdf, args...)
+}
+
+func (t Toolchain) pdftoppmCommand(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, t.Pdftoppm, args...)
+}
+
+func (t Toolchain) pdftotextCommand(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, t.Pdftotext, args...)
+}
+
+func (t Toolchain) mutoolCommand(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, t.Mutool, args...)
+}
+
+func (t Toolchain) ghostscriptCommand(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, t.Ghostscript, args...)
+}
+
+// runCommand runs the command with the rlimits in a span named after the
+// invocation and records the exit code, a command which failed to start has
+// no exit code
+func runCommand(ctx context.Context, name string, cmd *exec.Cmd, limits ResourceLimits, attributes ...attribute.KeyValue) error {
+	_, span := tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+	defer span.End()
+
+	span.SetAttributes(attribute.String("process.executable.path", cmd.Path))
+
+	err := startLimitedCommand(cmd, limits)
+	if err == nil {
+		err = cmd.Wait()
+	}
+
+	if cmd.ProcessState != nil {
+		span.SetAttributes(attribute.Int("process.exit.code", cmd.ProcessState.ExitCode()))
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// startLimitedCommand starts the command in its own process group with the
+// rlimits applied before it runs
+func startLimitedCommand(cmd *exec.Cmd, limits ResourceLimits) error {
+	prepareProcess(cmd)
+	limits.limitCommand(cmd)
+
+	return cmd.Start()
+}
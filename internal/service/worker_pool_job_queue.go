@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+// WorkerPoolJobQueue implements the usecase.JobQueue interface with a fixed
+// number of workers consuming a bounded queue
+type WorkerPoolJobQueue struct {
+	tasks      chan func(ctx context.Context)
+	ctx        context.Context
+	cancel     context.CancelFunc
+	mutex      sync.RWMutex
+	isClosed   bool
+	workers    sync.WaitGroup
+	retryAfter time.Duration
+}
+
+// NewWorkerPoolJobQueue starts the workers, Enqueue fails once queueSize tasks
+// are waiting and suggests to retry after retryAfter
+func NewWorkerPoolJobQueue(workers, queueSize int, retryAfter time.Duration) *WorkerPoolJobQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := &WorkerPoolJobQueue{
+		tasks:      make(chan func(ctx context.Context), queueSize),
+		ctx:        ctx,
+		cancel:     cancel,
+		retryAfter: retryAfter,
+	}
+
+	queue.workers.Add(workers)
+	for range workers {
+		go queue.work()
+	}
+
+	return queue
+}
+
+// Enqueue schedules the task without blocking
+func (q *WorkerPoolJobQueue) Enqueue(task func(ctx context.Context)) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.isClosed {
+		return usecase.ErrQueueClosed
+	}
+
+	select {
+	case q.tasks <- task:
+		return nil
+	default:
+		return &usecase.RetryableError{
+			Err:        usecase.ErrQueueFull,
+			RetryAfter: q.retryAfter,
+		}
+	}
+}
+
+// Shutdown stops accepting tasks and waits for the queued and running tasks
+// to finish. When ctx is done first, the context of the remaining tasks is
+// cancelled and Shutdown returns once they have returned.
+func (q *WorkerPoolJobQueue) Shutdown(ctx context.Context) error {
+	q.mutex.Lock()
+	if !q.isClosed {
+		q.isClosed = true
+		close(q.tasks)
+	}
+	q.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Len returns the number of tasks waiting for a worker
+func (q *WorkerPoolJobQueue) Len() int {
+	return len(q.tasks)
+}
+
+// Cap returns the maximum number of waiting tasks
+func (q *WorkerPoolJobQueue) Cap() int {
+	return cap(q.tasks)
+}
+
+// IsClosed is true once Shutdown is called
+func (q *WorkerPoolJobQueue) IsClosed() bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.isClosed
+}
+
+func (q *WorkerPoolJobQueue) work() {
+	defer q.workers.Done()
+
+	for task := range q.tasks {
+		task(q.ctx)
+	}
+}
@@ -0,0 +1,144 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/service"
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestWorkerPoolJobQueue_Enqueue(t *testing.T) {
+	queue := service.NewWorkerPoolJobQueue(2, 4, time.Second)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	completed := 0
+	for range 4 {
+		wg.Add(1)
+		err := queue.Enqueue(func(ctx context.Context) {
+			defer wg.Done()
+			mutex.Lock()
+			completed++
+			mutex.Unlock()
+		})
+		if err != nil {
+			t.Fatalf("failed to enqueue task: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if completed != 4 {
+		t.Errorf("expected 4 completed tasks, got %d", completed)
+	}
+}
+
+func TestWorkerPoolJobQueue_EnqueueWhenFull(t *testing.T) {
+	queue := service.NewWorkerPoolJobQueue(1, 1, 3*time.Second)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	if err := queue.Enqueue(func(ctx context.Context) {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatalf("failed to enqueue running task: %v", err)
+	}
+	<-started
+
+	if err := queue.Enqueue(func(ctx context.Context) {}); err != nil {
+		t.Fatalf("failed to enqueue waiting task: %v", err)
+	}
+
+	if queue.Len() != 1 {
+		t.Errorf("expected 1 waiting task, got %d", queue.Len())
+	}
+
+	err := queue.Enqueue(func(ctx context.Context) {})
+	if !errors.Is(err, usecase.ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+
+	var retryableErr *usecase.RetryableError
+	if !errors.As(err, &retryableErr) || retryableErr.RetryAfter != 3*time.Second {
+		t.Errorf("expected retry after 3s, got %v", err)
+	}
+}
+
+func TestWorkerPoolJobQueue_Shutdown(t *testing.T) {
+	tests := []struct {
+		name              string
+		taskDuration      time.Duration
+		shutdownTimeout   time.Duration
+		expectedError     error
+		isTaskCancelled   bool
+		expectedCompleted int
+	}{
+		{
+			name:              "drain tasks within deadline",
+			taskDuration:      10 * time.Millisecond,
+			shutdownTimeout:   time.Second,
+			expectedCompleted: 2,
+		},
+		{
+			name:              "cancel tasks after deadline",
+			taskDuration:      time.Minute,
+			shutdownTimeout:   20 * time.Millisecond,
+			expectedError:     context.DeadlineExceeded,
+			isTaskCancelled:   true,
+			expectedCompleted: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := service.NewWorkerPoolJobQueue(1, 2, time.Second)
+
+			var mutex sync.Mutex
+			completed, cancelled := 0, 0
+			for range 2 {
+				err := queue.Enqueue(func(ctx context.Context) {
+					select {
+					case <-time.After(tt.taskDuration):
+					case <-ctx.Done():
+						mutex.Lock()
+						cancelled++
+						mutex.Unlock()
+					}
+					mutex.Lock()
+					completed++
+					mutex.Unlock()
+				})
+				if err != nil {
+					t.Fatalf("failed to enqueue task: %v", err)
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.shutdownTimeout)
+			defer cancel()
+
+			err := queue.Shutdown(ctx)
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+
+			if completed != tt.expectedCompleted {
+				t.Errorf("expected %d completed tasks, got %d", tt.expectedCompleted, completed)
+			}
+
+			if (cancelled > 0) != tt.isTaskCancelled {
+				t.Errorf("expected cancelled tasks: %v, got %d", tt.isTaskCancelled, cancelled)
+			}
+
+			err = queue.Enqueue(func(ctx context.Context) {})
+			if !errors.Is(err, usecase.ErrQueueClosed) {
+				t.Errorf("expected ErrQueueClosed after shutdown, got %v", err)
+			}
+		})
+	}
+}
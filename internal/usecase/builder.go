@@ -1,7 +1,11 @@
 package usecase
 
-import "github.com/elct9620/pdf64/internal/entity"
+import (
+	"context"
+
+	"github.com/elct9620/pdf64/internal/entity"
+)
 
 type FileBuilder interface {
-	BuildFromPath(path string) (*entity.File, error)
+	BuildFromPath(ctx context.Context, path string) (*entity.File, error)
 }
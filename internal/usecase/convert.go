@@ -3,67 +3,392 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elct9620/pdf64/internal/entity"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/elct9620/pdf64/internal/usecase")
+
 var (
 	ErrPasswordRequired = errors.New("password is required for encrypted PDF")
+	ErrInvalidPageRange = errors.New("invalid page range")
+	ErrInvalidSize      = errors.New("invalid image size")
+	ErrOutputTooLarge   = errors.New("output is too large")
 )
 
+// ConvertLimits bound a single conversion, zero values are unlimited.
+// StreamBatchSize is the pages rendered together when streaming, zero
+// renders them one by one.
+type ConvertLimits struct {
+	MaxPages        int
+	MaxOutputBytes  int64
+	StreamBatchSize int
+}
+
 type ConvertInput struct {
-	FilePath string
-	Password string
-	Density  string
-	Quality  int
-	Merge    bool
+	FilePath  string
+	Password  string
+	Density   string
+	Quality   int
+	Merge     bool
+	Pages     string
+	Format    string
+	Width     int
+	Height    int
+	MaxPixels int
+	Renderer  string
+	// IncludeText attaches the text layer of the pages to each image
+	IncludeText bool
 }
 
 type ConvertOutput struct {
-	FileId        string
-	EncodedImages []string
+	FileId   string
+	Images   []Image
+	Pages    []int
+	Renderer Renderer
+}
+
+// TextOutput is the text layer of the selected pages
+type TextOutput struct {
+	FileId string
+	Pages  []PageText
 }
 
 type ConvertUsecase struct {
 	builder   FileBuilder
 	converter ImageConvertService
 	decrypter PdfDecryptService
+	counter   PdfPageCountService
+	texter    PdfTextService
+	metrics   ConvertMetrics
+	limits    ConvertLimits
 }
 
-func NewConvertUsecase(builder FileBuilder, converter ImageConvertService, decrypter PdfDecryptService) *ConvertUsecase {
+func NewConvertUsecase(builder FileBuilder, converter ImageConvertService, decrypter PdfDecryptService, counter PdfPageCountService, texter PdfTextService, metrics ConvertMetrics, limits ConvertLimits) *ConvertUsecase {
 	return &ConvertUsecase{
 		builder:   builder,
 		converter: converter,
 		decrypter: decrypter,
+		counter:   counter,
+		texter:    texter,
+		metrics:   metrics,
+		limits:    limits,
 	}
 }
 
-func (u *ConvertUsecase) Execute(ctx context.Context, input *ConvertInput) (*ConvertOutput, error) {
-	file, err := u.builder.BuildFromPath(input.FilePath)
+func (u *ConvertUsecase) Execute(ctx context.Context, input *ConvertInput) (output *ConvertOutput, err error) {
+	ctx, span := startConvertSpan(ctx, "ConvertUsecase.Execute", input)
+	defer func() { endSpan(span, err) }()
+
+	file, err := u.Prepare(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.Convert(ctx, file, input)
+}
+
+// Prepare validates the input and builds the file without converting it
+func (u *ConvertUsecase) Prepare(ctx context.Context, input *ConvertInput) (*entity.File, error) {
+	if _, err := ParseImageFormat(input.Format); err != nil {
+		return nil, err
+	}
+
+	if _, err := ParseRenderer(input.Renderer); err != nil {
+		return nil, err
+	}
+
+	if input.Width < 0 || input.Height < 0 || input.MaxPixels < 0 {
+		return nil, ErrInvalidSize
+	}
+
+	file, err := u.builder.BuildFromPath(ctx, input.FilePath)
 	if err != nil {
 		return nil, err
 	}
 
 	isPasswordGiven := input.Password != ""
-	if file.IsEncrypted() {
-		if !isPasswordGiven {
-			return nil, ErrPasswordRequired
-		}
+	if file.IsEncrypted() && !isPasswordGiven {
+		return nil, ErrPasswordRequired
+	}
 
-		if err = u.decrypter.Decrypt(ctx, file, input.Password); err != nil {
+	return file, nil
+}
+
+// Convert converts a file built by Prepare
+func (u *ConvertUsecase) Convert(ctx context.Context, file *entity.File, input *ConvertInput) (*ConvertOutput, error) {
+	defer u.metrics.ConversionStarted()()
+
+	options, err := u.resolveOptions(ctx, file, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, release, err := u.admit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	images, err := u.converter.Convert(ctx, file, options)
+	if err != nil {
+		return nil, err
+	}
+	u.metrics.ObserveOutput(len(options.Pages), images)
+
+	if _, err := u.checkOutputSize(0, images); err != nil {
+		return nil, err
+	}
+
+	if input.IncludeText {
+		if err := u.attachText(ctx, file, options.Pages, images); err != nil {
 			return nil, err
 		}
 	}
 
-	images, err := u.converter.Convert(ctx, file, ImageConvertOptions{
-		Density: input.Density,
-		Quality: input.Quality,
-		Merge:   input.Merge,
-	})
+	output := &ConvertOutput{
+		FileId: file.Id(),
+		Images: images,
+		Pages:  options.Pages,
+	}
+	if len(images) > 0 {
+		output.Renderer = images[0].Renderer
+	}
+
+	return output, nil
+}
+
+// Stream converts the pages in batches of StreamBatchSize and emits the
+// images of each batch as soon as it is rendered, merged output is emitted
+// as a single image once complete. The whole stream is admitted once.
+func (u *ConvertUsecase) Stream(ctx context.Context, input *ConvertInput, emit func(fileId string, image *Image) error) (err error) {
+	ctx, span := startConvertSpan(ctx, "ConvertUsecase.Stream", input)
+	defer func() { endSpan(span, err) }()
+
+	file, err := u.Prepare(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	defer u.metrics.ConversionStarted()()
+
+	options, err := u.resolveOptions(ctx, file, input)
+	if err != nil {
+		return err
+	}
+
+	ctx, release, err := u.admit(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	batches := [][]int{options.Pages}
+	if !options.Merge {
+		batches = streamBatches(options.Pages, u.limits.StreamBatchSize)
+	}
+
+	var written int64
+	for _, pages := range batches {
+		batchOptions := options
+		batchOptions.Pages = pages
+		if options.MaxOutputBytes > 0 {
+			// Zero would be unlimited, the limit is reached with any image
+			batchOptions.MaxOutputBytes = max(options.MaxOutputBytes-written, 1)
+		}
+
+		images, err := u.converter.Convert(ctx, file, batchOptions)
+		if err != nil {
+			return err
+		}
+		u.metrics.ObserveOutput(len(pages), images)
+
+		written, err = u.checkOutputSize(written, images)
+		if err != nil {
+			return err
+		}
+
+		if input.IncludeText {
+			if err := u.attachText(ctx, file, pages, images); err != nil {
+				return err
+			}
+		}
+
+		for i := range images {
+			if err := emit(file.Id(), &images[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// admit holds a single admission for the rendering and text extraction of a
+// conversion when the converter implements ConvertAdmission
+func (u *ConvertUsecase) admit(ctx context.Context) (context.Context, func(), error) {
+	admission, ok := u.converter.(ConvertAdmission)
+	if !ok {
+		return ctx, func() {}, nil
+	}
+
+	return admission.Admit(ctx)
+}
+
+// streamBatches splits the pages into batches of at most size pages
+func streamBatches(pages []int, size int) [][]int {
+	size = max(size, 1)
+
+	batches := make([][]int, 0, (len(pages)+size-1)/size)
+	for start := 0; start < len(pages); start += size {
+		batches = append(batches, pages[start:min(start+size, len(pages))])
+	}
+
+	return batches
+}
+
+// ExtractText returns the text layer of the selected pages without rendering
+// them, the conversion parameters of the input are validated but unused
+func (u *ConvertUsecase) ExtractText(ctx context.Context, input *ConvertInput) (output *TextOutput, err error) {
+	ctx, span := startConvertSpan(ctx, "ConvertUsecase.ExtractText", input)
+	defer func() { endSpan(span, err) }()
+
+	file, err := u.Prepare(ctx, input)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ConvertOutput{
-		FileId:        file.Id(),
-		EncodedImages: images,
+	options, err := u.resolveOptions(ctx, file, input)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := u.texter.Text(ctx, file, options.Pages)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TextOutput{
+		FileId: file.Id(),
+		Pages:  pages,
 	}, nil
 }
+
+// attachText sets the text of the pages to the images rendering them, a
+// merged image holds the text of every page separated by form feeds
+func (u *ConvertUsecase) attachText(ctx context.Context, file *entity.File, pages []int, images []Image) error {
+	texts, err := u.texter.Text(ctx, file, pages)
+	if err != nil {
+		return err
+	}
+
+	byPage := make(map[int]string, len(texts))
+	merged := make([]string, 0, len(texts))
+	for _, text := range texts {
+		byPage[text.Page] = text.Text
+		merged = append(merged, text.Text)
+	}
+
+	for i := range images {
+		text := byPage[images[i].Page]
+		if images[i].Page == 0 {
+			text = strings.Join(merged, "\f")
+		}
+		images[i].Text = &text
+	}
+
+	return nil
+}
+
+// resolveOptions decrypts the file when needed and resolves the pages to render
+func (u *ConvertUsecase) resolveOptions(ctx context.Context, file *entity.File, input *ConvertInput) (ImageConvertOptions, error) {
+	format, err := ParseImageFormat(input.Format)
+	if err != nil {
+		return ImageConvertOptions{}, err
+	}
+
+	renderer, err := ParseRenderer(input.Renderer)
+	if err != nil {
+		return ImageConvertOptions{}, err
+	}
+
+	if file.IsEncrypted() {
+		if err = u.decrypter.Decrypt(ctx, file, input.Password); err != nil {
+			return ImageConvertOptions{}, err
+		}
+	}
+
+	pageCount, err := u.counter.PageCount(ctx, file)
+	if err != nil {
+		return ImageConvertOptions{}, err
+	}
+
+	pages, err := ParsePageRange(input.Pages, pageCount)
+	if err != nil {
+		return ImageConvertOptions{}, err
+	}
+
+	if u.limits.MaxPages > 0 && len(pages) > u.limits.MaxPages {
+		return ImageConvertOptions{}, fmt.Errorf("%w: %d pages exceed the limit of %d", ErrTooManyPages, len(pages), u.limits.MaxPages)
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("pdf64.file.id", file.Id()),
+		attribute.Int("pdf64.document.page_count", pageCount),
+		attribute.Int("pdf64.page_count", len(pages)),
+	)
+
+	return ImageConvertOptions{
+		Density:   input.Density,
+		Quality:   input.Quality,
+		Merge:     input.Merge,
+		Pages:     pages,
+		Format:    format,
+		Width:     input.Width,
+		Height:    input.Height,
+		MaxPixels: input.MaxPixels,
+		Renderer:  renderer,
+
+		MaxOutputBytes: u.limits.MaxOutputBytes,
+	}, nil
+}
+
+// checkOutputSize adds the size of the images to the written bytes and fails
+// once the total exceeds MaxOutputBytes, the converter already stops reading
+// images beyond the limit
+func (u *ConvertUsecase) checkOutputSize(written int64, images []Image) (int64, error) {
+	for i := range images {
+		written += int64(len(images[i].Data))
+	}
+
+	if u.limits.MaxOutputBytes > 0 && written > u.limits.MaxOutputBytes {
+		return written, fmt.Errorf("%w: exceeds %d bytes", ErrOutputTooLarge, u.limits.MaxOutputBytes)
+	}
+
+	return written, nil
+}
+
+func startConvertSpan(ctx context.Context, name string, input *ConvertInput) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("pdf64.density", input.Density),
+		attribute.Int("pdf64.quality", input.Quality),
+		attribute.String("pdf64.format", input.Format),
+		attribute.Bool("pdf64.merge", input.Merge),
+		attribute.String("pdf64.renderer", input.Renderer),
+	))
+}
+
+// endSpan marks the span as failed when err is set and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+)
+
+type ImageFormat string
+
+const (
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatWebP ImageFormat = "webp"
+	ImageFormatAVIF ImageFormat = "avif"
+	ImageFormatTIFF ImageFormat = "tiff"
+)
+
+// SupportedImageFormats lists the output formats in order of preference
+var SupportedImageFormats = []ImageFormat{
+	ImageFormatJPEG,
+	ImageFormatPNG,
+	ImageFormatWebP,
+	ImageFormatAVIF,
+	ImageFormatTIFF,
+}
+
+var imageFormatAliases = map[string]ImageFormat{
+	"jpg": ImageFormatJPEG,
+	"tif": ImageFormatTIFF,
+}
+
+// ParseImageFormat resolves a case-insensitive format name or common alias
+func ParseImageFormat(name string) (ImageFormat, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if format, ok := imageFormatAliases[name]; ok {
+		return format, nil
+	}
+
+	for _, format := range SupportedImageFormats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedFormat, name)
+}
+
+// Extension returns the file extension without the leading dot
+func (f ImageFormat) Extension() string {
+	if f == ImageFormatJPEG {
+		return "jpg"
+	}
+	return string(f)
+}
+
+// MimeType returns the media type used in data URIs
+func (f ImageFormat) MimeType() string {
+	return "image/" + string(f)
+}
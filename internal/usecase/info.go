@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DocumentMetadata is read from the document information dictionary
+type DocumentMetadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+	Producer string
+}
+
+// PageInfo describes a page, Width and Height are in points with the page
+// rotation applied. HasText is true when the page uses any font.
+type PageInfo struct {
+	Number   int
+	Width    float64
+	Height   float64
+	Rotation int
+	HasText  bool
+}
+
+// DocumentInfo describes the structure of a PDF without rendering it
+type DocumentInfo struct {
+	PdfVersion string
+	Metadata   DocumentMetadata
+	Pages      []PageInfo
+}
+
+type InfoInput struct {
+	FilePath string
+	Password string
+}
+
+// InfoOutput reports whether the uploaded document is encrypted, Info
+// describes its decrypted content and is empty for an encrypted document
+// described without a password
+type InfoOutput struct {
+	FileId      string
+	IsEncrypted bool
+	Info        *DocumentInfo
+}
+
+type InfoUsecase struct {
+	builder   FileBuilder
+	decrypter PdfDecryptService
+	inspector PdfInfoService
+}
+
+func NewInfoUsecase(builder FileBuilder, decrypter PdfDecryptService, inspector PdfInfoService) *InfoUsecase {
+	return &InfoUsecase{
+		builder:   builder,
+		decrypter: decrypter,
+		inspector: inspector,
+	}
+}
+
+// Execute builds the file, decrypts it with the password when encrypted and
+// reads its structure. Without a password only the encryption is reported.
+func (u *InfoUsecase) Execute(ctx context.Context, input *InfoInput) (output *InfoOutput, err error) {
+	ctx, span := tracer.Start(ctx, "InfoUsecase.Execute")
+	defer func() { endSpan(span, err) }()
+
+	file, err := u.builder.BuildFromPath(ctx, input.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	isEncrypted := file.IsEncrypted()
+	if isEncrypted {
+		if input.Password == "" {
+			return &InfoOutput{
+				FileId:      file.Id(),
+				IsEncrypted: true,
+				Info:        &DocumentInfo{},
+			}, nil
+		}
+
+		if err := u.decrypter.Decrypt(ctx, file, input.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	info, err := u.inspector.Info(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.String("pdf64.file.id", file.Id()),
+		attribute.Int("pdf64.document.page_count", len(info.Pages)),
+	)
+
+	return &InfoOutput{
+		FileId:      file.Id(),
+		IsEncrypted: isEncrypted,
+		Info:        info,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"os"
+
+	"github.com/elct9620/pdf64/internal/entity"
+)
+
+var (
+	ErrJobNotFound    = errors.New("job not found")
+	ErrJobNotFinished = errors.New("job is not finished")
+	ErrQueueFull      = errors.New("job queue is full")
+	ErrQueueClosed    = errors.New("job queue is closed")
+
+	ErrInvalidCallbackURL = errors.New("invalid callback url")
+)
+
+type JobUsecase struct {
+	convertUsecase *ConvertUsecase
+	webhookUsecase *WebhookUsecase
+	repository     JobRepository
+	queue          JobQueue
+}
+
+func NewJobUsecase(convertUsecase *ConvertUsecase, webhookUsecase *WebhookUsecase, repository JobRepository, queue JobQueue) *JobUsecase {
+	return &JobUsecase{
+		convertUsecase: convertUsecase,
+		webhookUsecase: webhookUsecase,
+		repository:     repository,
+		queue:          queue,
+	}
+}
+
+// Submit validates the input and queues the conversion, the job shares the
+// id of the converted file. Once queued, the job takes ownership of the file
+// at FilePath and removes it when finished, a job which cannot be queued is
+// discarded with its file. The callbackURL is optional and notified when the
+// job finishes.
+func (u *JobUsecase) Submit(ctx context.Context, input *ConvertInput, callbackURL string) (*entity.Job, error) {
+	if callbackURL != "" && !isValidCallbackURL(callbackURL) {
+		return nil, ErrInvalidCallbackURL
+	}
+
+	file, err := u.convertUsecase.Prepare(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	job := entity.NewJob(file.Id(), callbackURL)
+	if err := u.repository.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	queued := *job
+	err = u.queue.Enqueue(func(ctx context.Context) {
+		defer os.Remove(input.FilePath)
+		u.run(ctx, &queued, file, input)
+	})
+	if err != nil {
+		os.Remove(input.FilePath)
+		_ = u.repository.Delete(context.WithoutCancel(ctx), job.Id())
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (u *JobUsecase) run(ctx context.Context, job *entity.Job, file *entity.File, input *ConvertInput) {
+	job.Start()
+
+	// A job which cannot be marked as running still fails and notifies the
+	// callback instead of staying queued
+	var output *ConvertOutput
+	err := u.repository.Save(ctx, job)
+	if err == nil {
+		output, err = u.convertUsecase.Convert(ctx, file, input)
+	}
+
+	if err == nil {
+		err = u.repository.SaveResult(ctx, job.Id(), output)
+	}
+
+	if err != nil {
+		slog.Error("Job failed", "job_id", job.Id(), "error", err)
+		job.Fail(err)
+		output = nil
+	} else {
+		job.Succeed()
+	}
+
+	if err := u.repository.Save(context.WithoutCancel(ctx), job); err != nil {
+		return
+	}
+
+	if job.CallbackURL() != "" {
+		_ = u.webhookUsecase.Deliver(ctx, job, output)
+	}
+}
+
+func isValidCallbackURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func (u *JobUsecase) Find(ctx context.Context, id string) (*entity.Job, error) {
+	return u.repository.Find(ctx, id)
+}
+
+// Result returns the conversion output of a succeeded job, or the failure
+// reason of a failed job
+func (u *JobUsecase) Result(ctx context.Context, id string) (*ConvertOutput, error) {
+	job, err := u.repository.Find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status() {
+	case entity.JobStatusSucceeded:
+		return u.repository.FindResult(ctx, id)
+	case entity.JobStatusFailed:
+		return nil, job.Err()
+	default:
+		return nil, ErrJobNotFinished
+	}
+}
@@ -0,0 +1,22 @@
+package usecase
+
+import "time"
+
+type ConvertPhase string
+
+const (
+	ConvertPhaseDecrypt   ConvertPhase = "decrypt"
+	ConvertPhaseRasterize ConvertPhase = "rasterize"
+	ConvertPhaseEncode    ConvertPhase = "encode"
+)
+
+// ConvertMetrics records the performance of conversions
+type ConvertMetrics interface {
+	// ConversionStarted counts an in-flight conversion until finished is called
+	ConversionStarted() (finished func())
+	ObservePhase(phase ConvertPhase, duration time.Duration)
+	// ObserveOutput records the rendered pages and the size of the images
+	ObserveOutput(pages int, images []Image)
+	// ObserveSubprocessFailure records an external command which failed to run
+	ObserveSubprocessFailure(command string)
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParsePageRange resolves a page range specification like "1-3,7,10-" into
+// a sorted list of unique 1-based page numbers within the page count.
+// An empty specification selects every page.
+func ParsePageRange(spec string, pageCount int) ([]int, error) {
+	if pageCount < 1 {
+		return nil, fmt.Errorf("%w: document has no pages", ErrInvalidPageRange)
+	}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return pageSequence(1, pageCount), nil
+	}
+
+	selected := make(map[int]bool)
+	for _, segment := range strings.Split(spec, ",") {
+		first, last, err := parsePageSegment(strings.TrimSpace(segment), pageCount)
+		if err != nil {
+			return nil, err
+		}
+
+		for page := first; page <= last; page++ {
+			selected[page] = true
+		}
+	}
+
+	pages := make([]int, 0, len(selected))
+	for page := range selected {
+		pages = append(pages, page)
+	}
+	sort.Ints(pages)
+
+	return pages, nil
+}
+
+func parsePageSegment(segment string, pageCount int) (int, int, error) {
+	if segment == "" {
+		return 0, 0, fmt.Errorf("%w: empty segment", ErrInvalidPageRange)
+	}
+
+	firstStr, lastStr, isRange := strings.Cut(segment, "-")
+	if !isRange {
+		lastStr = firstStr
+	}
+
+	first := 1
+	if firstStr != "" {
+		page, err := parsePageNumber(firstStr, pageCount)
+		if err != nil {
+			return 0, 0, err
+		}
+		first = page
+	}
+
+	last := pageCount
+	if lastStr != "" {
+		page, err := parsePageNumber(lastStr, pageCount)
+		if err != nil {
+			return 0, 0, err
+		}
+		last = page
+	}
+
+	if firstStr == "" && lastStr == "" {
+		return 0, 0, fmt.Errorf("%w: %q has no bounds", ErrInvalidPageRange, segment)
+	}
+
+	if first > last {
+		return 0, 0, fmt.Errorf("%w: %q is descending", ErrInvalidPageRange, segment)
+	}
+
+	return first, last, nil
+}
+
+func parsePageNumber(value string, pageCount int) (int, error) {
+	page, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a page number", ErrInvalidPageRange, value)
+	}
+
+	if page < 1 || page > pageCount {
+		return 0, fmt.Errorf("%w: page %d is out of range 1-%d", ErrInvalidPageRange, page, pageCount)
+	}
+
+	return page, nil
+}
+
+func pageSequence(first, last int) []int {
+	pages := make([]int, 0, last-first+1)
+	for page := first; page <= last; page++ {
+		pages = append(pages, page)
+	}
+	return pages
+}
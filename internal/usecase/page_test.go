@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/elct9620/pdf64/internal/usecase"
+)
+
+func TestParsePageRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		pageCount int
+		expected  []int
+		error     error
+	}{
+		{
+			name:      "Empty Specification",
+			spec:      "",
+			pageCount: 3,
+			expected:  []int{1, 2, 3},
+		},
+		{
+			name:      "Mixed Segments",
+			spec:      "1-3,7,10-",
+			pageCount: 12,
+			expected:  []int{1, 2, 3, 7, 10, 11, 12},
+		},
+		{
+			name:      "Open Start",
+			spec:      "-2",
+			pageCount: 5,
+			expected:  []int{1, 2},
+		},
+		{
+			name:      "Overlapping Segments",
+			spec:      "4, 2-4 ,1",
+			pageCount: 5,
+			expected:  []int{1, 2, 3, 4},
+		},
+		{
+			name:      "Page Out Of Range",
+			spec:      "1-6",
+			pageCount: 5,
+			error:     usecase.ErrInvalidPageRange,
+		},
+		{
+			name:      "Descending Segment",
+			spec:      "3-1",
+			pageCount: 5,
+			error:     usecase.ErrInvalidPageRange,
+		},
+		{
+			name:      "Empty Segment",
+			spec:      "1,,2",
+			pageCount: 5,
+			error:     usecase.ErrInvalidPageRange,
+		},
+		{
+			name:      "Not A Number",
+			spec:      "first",
+			pageCount: 5,
+			error:     usecase.ErrInvalidPageRange,
+		},
+		{
+			name:      "Zero Page",
+			spec:      "0",
+			pageCount: 5,
+			error:     usecase.ErrInvalidPageRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages, err := usecase.ParsePageRange(tt.spec, tt.pageCount)
+			if !errors.Is(err, tt.error) {
+				t.Fatalf("expected error %v, got %v", tt.error, err)
+			}
+
+			if !slices.Equal(pages, tt.expected) {
+				t.Errorf("expected pages %v, got %v", tt.expected, pages)
+			}
+		})
+	}
+}
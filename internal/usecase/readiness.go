@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+)
+
+// DependencyCheck verifies an external dependency is usable, the detail
+// describes the healthy dependency, e.g. the version of a binary
+type DependencyCheck interface {
+	Name() string
+	Check(ctx context.Context) (detail string, err error)
+}
+
+// DependencyStatus is the result of a single check, Err is set when the
+// dependency is not usable
+type DependencyStatus struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+func (s DependencyStatus) IsReady() bool {
+	return s.Err == nil
+}
+
+type ReadinessReport struct {
+	Dependencies []DependencyStatus
+}
+
+// IsReady is true when every dependency is ready
+func (r *ReadinessReport) IsReady() bool {
+	for _, dependency := range r.Dependencies {
+		if !dependency.IsReady() {
+			return false
+		}
+	}
+
+	return true
+}
+
+type ReadinessUsecase struct {
+	checks []DependencyCheck
+}
+
+func NewReadinessUsecase(checks ...DependencyCheck) *ReadinessUsecase {
+	return &ReadinessUsecase{
+		checks: checks,
+	}
+}
+
+// Check runs every check concurrently, the report keeps the order of the checks
+func (u *ReadinessUsecase) Check(ctx context.Context) *ReadinessReport {
+	report := &ReadinessReport{
+		Dependencies: make([]DependencyStatus, len(u.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range u.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			detail, err := check.Check(ctx)
+			report.Dependencies[i] = DependencyStatus{
+				Name:   check.Name(),
+				Detail: detail,
+				Err:    err,
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
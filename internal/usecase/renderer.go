@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnsupportedRenderer = errors.New("unsupported renderer")
+)
+
+// Renderer names the backend rasterizing the PDF pages, an empty renderer
+// selects the configured default
+type Renderer string
+
+const (
+	RendererDefault     Renderer = ""
+	RendererImageMagick Renderer = "imagemagick"
+	RendererPdftoppm    Renderer = "pdftoppm"
+	RendererMutool      Renderer = "mutool"
+	RendererGhostscript Renderer = "ghostscript"
+)
+
+// SupportedRenderers lists the available rendering backends
+var SupportedRenderers = []Renderer{
+	RendererImageMagick,
+	RendererPdftoppm,
+	RendererMutool,
+	RendererGhostscript,
+}
+
+// ParseRenderer resolves a case-insensitive renderer name, an empty name is
+// the default renderer
+func ParseRenderer(name string) (Renderer, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return RendererDefault, nil
+	}
+
+	for _, renderer := range SupportedRenderers {
+		if string(renderer) == name {
+			return renderer, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedRenderer, name)
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/elct9620/pdf64/internal/entity"
+)
+
+type JobRepository interface {
+	Save(ctx context.Context, job *entity.Job) error
+	Find(ctx context.Context, id string) (*entity.Job, error)
+	Delete(ctx context.Context, id string) error
+	SaveResult(ctx context.Context, id string, output *ConvertOutput) error
+	FindResult(ctx context.Context, id string) (*ConvertOutput, error)
+}
@@ -2,20 +2,126 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"time"
 
 	"github.com/elct9620/pdf64/internal/entity"
 )
 
+var (
+	ErrFetchForbidden = errors.New("document url is not allowed")
+	ErrFetchFailed    = errors.New("failed to fetch document")
+	ErrFileTooLarge   = errors.New("document is too large")
+	ErrServerBusy     = errors.New("server is busy")
+
+	ErrResourceLimitExceeded = errors.New("resource limit exceeded")
+	ErrConversionTimeout     = errors.New("conversion timed out")
+
+	ErrInvalidPassword     = errors.New("invalid password for encrypted PDF")
+	ErrUnsupportedDocument = errors.New("document is not a PDF")
+	ErrEmptyDocument       = errors.New("document is empty")
+	ErrCorruptDocument     = errors.New("document is corrupt")
+	ErrTooManyPages        = errors.New("document has too many pages")
+)
+
+// RetryableError is a temporary failure, the request may be retried after
+// RetryAfter
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// ImageConvertOptions configures the conversion, Pages lists the 1-based
+// page numbers to render and an empty list renders every page.
+// An empty Format falls back to JPEG.
+// Each page is fit into Width and Height preserving the aspect ratio and
+// shrunk to at most MaxPixels, zero values leave the page size unchanged.
+// Renderer selects the rendering backend, empty uses the default one.
+// MaxOutputBytes bounds the total size of the images, zero is unlimited.
 type ImageConvertOptions struct {
-	Density string
-	Quality int
-	Merge   bool
+	Density   string
+	Quality   int
+	Merge     bool
+	Pages     []int
+	Format    ImageFormat
+	Width     int
+	Height    int
+	MaxPixels int
+	Renderer  Renderer
+
+	MaxOutputBytes int64
+}
+
+// Image is a converted image, Page is the 1-based page number or zero when
+// the image merges multiple pages. Renderer is the backend which produced it.
+// Text is the text layer of its pages, nil unless requested.
+type Image struct {
+	Page     int
+	Width    int
+	Height   int
+	MimeType string
+	Data     []byte
+	Renderer Renderer
+	Text     *string
+}
+
+// DataURI encodes the image as a base64 data URI
+func (i *Image) DataURI() string {
+	return "data:" + i.MimeType + ";base64," + base64.StdEncoding.EncodeToString(i.Data)
 }
 
 type ImageConvertService interface {
-	Convert(ctx context.Context, file *entity.File, options ImageConvertOptions) ([]string, error)
+	Convert(ctx context.Context, file *entity.File, options ImageConvertOptions) ([]Image, error)
+}
+
+// ConvertAdmission admits work spanning several conversions at once, the
+// calls made with the returned context are not admitted again and the
+// returned release must be called once the work is done
+type ConvertAdmission interface {
+	Admit(ctx context.Context) (context.Context, func(), error)
 }
 
 type PdfDecryptService interface {
 	Decrypt(ctx context.Context, file *entity.File, password string) error
 }
+
+type PdfPageCountService interface {
+	PageCount(ctx context.Context, file *entity.File) (int, error)
+}
+
+// PageText is the text layer of a page, empty when the page has none
+type PageText struct {
+	Page int
+	Text string
+}
+
+// PdfTextService extracts the text layer of the 1-based pages in order
+type PdfTextService interface {
+	Text(ctx context.Context, file *entity.File, pages []int) ([]PageText, error)
+}
+
+type PdfInfoService interface {
+	Info(ctx context.Context, file *entity.File) (*DocumentInfo, error)
+}
+
+// JobQueue runs tasks in the background, the context passed to a task is
+// cancelled when the queue is shutting down
+type JobQueue interface {
+	Enqueue(task func(ctx context.Context)) error
+}
+
+// DocumentFetcher downloads a remote document, the caller is responsible for
+// closing the returned reader
+type DocumentFetcher interface {
+	Fetch(ctx context.Context, url string) (io.ReadCloser, error)
+}
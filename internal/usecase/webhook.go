@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/elct9620/pdf64/internal/entity"
+)
+
+// WebhookNotifier sends a single signed callback request
+type WebhookNotifier interface {
+	Notify(ctx context.Context, url string, payload []byte) (statusCode int, err error)
+}
+
+// WebhookPresenter encodes the callback payload of a finished job
+type WebhookPresenter interface {
+	Present(job *entity.Job, output *ConvertOutput) ([]byte, error)
+}
+
+// WebhookRetryPolicy doubles the backoff after each failed attempt up to MaxBackoff
+type WebhookRetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type WebhookUsecase struct {
+	repository JobRepository
+	notifier   WebhookNotifier
+	presenter  WebhookPresenter
+	policy     WebhookRetryPolicy
+}
+
+func NewWebhookUsecase(repository JobRepository, notifier WebhookNotifier, presenter WebhookPresenter, policy WebhookRetryPolicy) *WebhookUsecase {
+	return &WebhookUsecase{
+		repository: repository,
+		notifier:   notifier,
+		presenter:  presenter,
+		policy:     policy,
+	}
+}
+
+// Deliver notifies the callback URL of the finished job and records every attempt
+func (u *WebhookUsecase) Deliver(ctx context.Context, job *entity.Job, output *ConvertOutput) error {
+	payload, err := u.presenter.Present(job, output)
+	if err != nil {
+		return err
+	}
+
+	backoff := u.policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		statusCode, err := u.notifier.Notify(ctx, job.CallbackURL(), payload)
+		job.RecordDelivery(entity.WebhookDelivery{
+			Attempt:     attempt,
+			StatusCode:  statusCode,
+			Err:         err,
+			DeliveredAt: time.Now(),
+		})
+
+		if saveErr := u.repository.Save(ctx, job); saveErr != nil {
+			return saveErr
+		}
+
+		if err == nil || attempt >= u.policy.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff = min(backoff*2, u.policy.MaxBackoff)
+	}
+}
@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Status string
+
+const (
+	StatusReady       Status = "ready"
+	StatusUnavailable Status = "unavailable"
+)
+
+type ServiceImpl interface {
+	Ready(ctx context.Context) *ReadinessResponse
+}
+
+// DependencyStatus describes a single dependency, Detail is e.g. the version
+// of a binary and Error explains why the dependency is unavailable
+type DependencyStatus struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status       Status             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+func Register(r chi.Router, impl ServiceImpl) {
+	r.Get("/readyz", GetReadiness(impl))
+}
+
+// GetReadiness responds with 503 when any dependency is unavailable
+func GetReadiness(impl ServiceImpl) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := impl.Ready(r.Context())
+
+		statusCode := http.StatusOK
+		if resp.Status != StatusReady {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(statusCode)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			slog.Error("Failed to encode JSON response", "error", err)
+		}
+	}
+}
@@ -5,25 +5,56 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ServiceImpl interface {
 	Convert(ctx context.Context, req *ConvertRequest) (*ConvertResponse, error)
+	ConvertRaw(ctx context.Context, req *ConvertRequest) (*ConvertRawResponse, error)
+	SubmitJob(ctx context.Context, req *ConvertRequest) (*JobResponse, error)
+	GetJob(ctx context.Context, id string) (*JobResponse, error)
+	GetJobResult(ctx context.Context, id string) (*ConvertResponse, error)
+	Info(ctx context.Context, req *ConvertRequest) (*InfoResponse, error)
+	Text(ctx context.Context, req *ConvertRequest) (*TextResponse, error)
 }
 
-func Register(r chi.Router, impl ServiceImpl) {
-	r.Post("/v1/convert", PostConvert(impl))
+// Options configures how the requests are parsed
+type Options struct {
+	// MaxFormMemory is the size of multipart forms kept in memory, the rest
+	// is stored in temporary files
+	MaxFormMemory int64
+	// MaxUploadSize is the largest accepted document in bytes, JSON bodies
+	// may carry it as base64. Zero accepts any size.
+	MaxUploadSize int64
 }
 
-func respondWithError(w http.ResponseWriter, r *http.Request, err Error, statusCode int, originalErr error) {
+func Register(r chi.Router, impl ServiceImpl, options Options) {
+	r.Post("/v1/convert", PostConvert(impl, options))
+	r.Post("/v1/info", PostInfo(impl, options))
+	r.Post("/v1/text", PostText(impl, options))
+	r.Post("/v1/jobs", PostJob(impl, options))
+	r.Get("/v1/jobs/{id}", GetJob(impl))
+	r.Get("/v1/jobs/{id}/result", GetJobResult(impl))
+}
+
+// RespondWithError writes err as the JSON error body, originalErr is only
+// logged
+func RespondWithError(w http.ResponseWriter, r *http.Request, err Error, statusCode int, originalErr error) {
 	if originalErr != nil {
 		ctx := r.Context()
 		httplog.LogEntrySetField(ctx, "error", slog.AnyValue(originalErr))
 	}
+	RecordErrorCode(r.Context(), err.Code)
+	trace.SpanFromContext(r.Context()).SetAttributes(attribute.Int("pdf64.error_code", int(err.Code)))
 
+	if err.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(err.RetryAfter))
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	if encodeErr := json.NewEncoder(w).Encode(err); encodeErr != nil {
@@ -32,6 +63,20 @@ func respondWithError(w http.ResponseWriter, r *http.Request, err Error, statusC
 	}
 }
 
+// RespondWithConvertError responds with err when it is an API error,
+// otherwise with an internal error which hides the details
+func RespondWithConvertError(w http.ResponseWriter, r *http.Request, err error) {
+	if apiErr, ok := err.(Error); ok {
+		RespondWithError(w, r, apiErr, apiErr.StatusCode(), err)
+		return
+	}
+
+	RespondWithError(w, r, Error{
+		Code:    ErrCodeInternal,
+		Message: "Conversion failed",
+	}, http.StatusInternalServerError, err)
+}
+
 func respondWithJSON(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -1,23 +1,83 @@
 package v1
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
+
+	"github.com/go-chi/httplog/v2"
 )
 
 type ConvertRequest struct {
-	Password string `json:"password"`
-	Density  string `json:"density"`
-	Quality  int    `json:"quality"`
-	Merge    bool   `json:"merge"`
-	File     io.ReadCloser
+	Password  string `json:"password"`
+	Density   string `json:"density"`
+	Quality   int    `json:"quality"`
+	Merge     bool   `json:"merge"`
+	Pages     string `json:"pages"`
+	Format    string `json:"format"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	MaxPixels int    `json:"max_pixels"`
+	// Renderer selects the rendering backend instead of the default one
+	Renderer string `json:"renderer"`
+	// IncludeText attaches the text layer of the pages to each image
+	IncludeText bool `json:"include_text"`
+	// CallbackURL is notified when an asynchronous job finishes
+	CallbackURL string `json:"callback_url"`
+	// URL is downloaded by the server when no File is uploaded
+	URL  string        `json:"url"`
+	File io.ReadCloser `json:"-"`
+}
+
+// Close releases the uploaded File, if any
+func (r *ConvertRequest) Close() error {
+	if r.File == nil {
+		return nil
+	}
+
+	return r.File.Close()
+}
+
+var (
+	errSourceRequired = Error{
+		Code:    ErrCodeBadRequest,
+		Message: "Either an uploaded file in data or a url is required",
+	}
+	errInvalidQuality = Error{
+		Code:    ErrCodeInvalidParameter,
+		Message: "quality must be an integer between 1 and 100",
+	}
+)
+
+// ConvertJSONRequest carries the document as base64 or a base64 data URI
+type ConvertJSONRequest struct {
+	ConvertRequest
+	Data string `json:"data"`
+}
+
+type ImageSize struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
 }
 
+// ConvertResponse contains the converted images, Pages[i] is the page number
+// of Data[i] or the pages contained in the single image when merged.
+// Sizes[i] is the pixel dimensions of Data[i].
 type ConvertResponse struct {
-	Id   string   `json:"id"`
-	Data []string `json:"data"`
+	Id       string      `json:"id"`
+	Data     []string    `json:"data"`
+	Pages    []int       `json:"pages"`
+	Sizes    []ImageSize `json:"sizes"`
+	Renderer string      `json:"renderer,omitempty"`
+	Text     []string    `json:"text,omitempty"`
 }
 
 // parseBoolFormValue parses a form value as boolean
@@ -28,62 +88,291 @@ func parseBoolFormValue(value string) bool {
 	return value == "true" || value == "yes" || value == "1"
 }
 
-func PostConvert(impl ServiceImpl) http.HandlerFunc {
+// parseSizeFormValue parses the named form value as a non-negative integer,
+// an empty value is considered 0 while invalid values are rejected
+func parseSizeFormValue(r *http.Request, name string) (int, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, Error{
+			Code:    ErrCodeInvalidParameter,
+			Message: fmt.Sprintf("%s must be a non-negative integer", name),
+		}
+	}
+
+	return number, nil
+}
+
+// parseQualityFormValue parses the quality form value, an empty value is
+// considered 0 while invalid values are rejected
+func parseQualityFormValue(r *http.Request) (int, error) {
+	value := r.FormValue("quality")
+	if value == "" {
+		return 0, nil
+	}
+
+	quality, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errInvalidQuality
+	}
+
+	return quality, validateQuality(quality)
+}
+
+// validateQuality accepts a quality between 1 and 100, zero uses the default
+func validateQuality(quality int) error {
+	if quality < 0 || quality > 100 {
+		return errInvalidQuality
+	}
+
+	return nil
+}
+
+// ParseConvertRequest reads the conversion parameters and uploaded file from a
+// multipart form or a JSON body, the caller is responsible for closing the
+// returned File
+func ParseConvertRequest(w http.ResponseWriter, r *http.Request, options Options) (*ConvertRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if options.MaxUploadSize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, jsonBodyLimit(options.MaxUploadSize))
+		}
+
+		return parseJSONConvertRequest(r, options.MaxUploadSize)
+	}
+
+	if options.MaxUploadSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, options.MaxUploadSize)
+	}
+
+	return parseMultipartConvertRequest(r, options.MaxFormMemory, options.MaxUploadSize)
+}
+
+// jsonEnvelopeBytes leaves room for the fields around the base64 data
+const jsonEnvelopeBytes = 64 << 10
+
+// jsonBodyLimit is the size of a JSON body carrying a document of
+// maxUploadSize bytes as base64, which the decoded document is checked against
+func jsonBodyLimit(maxUploadSize int64) int64 {
+	return (maxUploadSize+2)/3*4 + jsonEnvelopeBytes
+}
+
+// uploadTooLargeError reports a body exceeding maxUploadSize, it is false
+// for other errors
+func uploadTooLargeError(err error, maxUploadSize int64) (Error, bool) {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return Error{}, false
+	}
+
+	return uploadLimitError(maxUploadSize), true
+}
+
+func uploadLimitError(maxUploadSize int64) Error {
+	return Error{
+		Code:    ErrCodeMaxFileSize,
+		Message: fmt.Sprintf("Document is too large: exceeds the upload limit of %d bytes", maxUploadSize),
+	}
+}
+
+func parseJSONConvertRequest(r *http.Request, maxUploadSize int64) (*ConvertRequest, error) {
+	var body ConvertJSONRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		httplog.LogEntrySetField(r.Context(), "error", slog.AnyValue(err))
+		if apiErr, ok := uploadTooLargeError(err, maxUploadSize); ok {
+			return nil, apiErr
+		}
+		return nil, Error{
+			Code:    ErrCodeBadRequest,
+			Message: "Failed to parse JSON body",
+		}
+	}
+
+	if (body.Data == "") == (body.URL == "") {
+		return nil, errSourceRequired
+	}
+
+	if err := validateQuality(body.Quality); err != nil {
+		return nil, err
+	}
+
+	req := body.ConvertRequest
+	if body.Data == "" {
+		return &req, nil
+	}
+
+	data, err := decodeBase64Data(body.Data)
+	if err != nil {
+		httplog.LogEntrySetField(r.Context(), "error", slog.AnyValue(err))
+		return nil, Error{
+			Code:    ErrCodeBadRequest,
+			Message: "Data must be base64 encoded or a base64 data URI",
+		}
+	}
+
+	if maxUploadSize > 0 && int64(len(data)) > maxUploadSize {
+		return nil, uploadLimitError(maxUploadSize)
+	}
+
+	req.File = io.NopCloser(bytes.NewReader(data))
+
+	return &req, nil
+}
+
+// decodeBase64Data decodes plain base64 or the payload of a base64 data URI
+func decodeBase64Data(data string) ([]byte, error) {
+	if strings.HasPrefix(data, "data:") {
+		meta, payload, found := strings.Cut(data, ",")
+		if !found || !strings.HasSuffix(meta, ";base64") {
+			return nil, errors.New("data URI is not base64 encoded")
+		}
+		data = payload
+	}
+
+	return base64.StdEncoding.DecodeString(data)
+}
+
+func parseMultipartConvertRequest(r *http.Request, maxFormMemory, maxUploadSize int64) (*ConvertRequest, error) {
+	// URL-encoded forms are parsed as well and can only provide a url
+	err := r.ParseMultipartForm(maxFormMemory)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		httplog.LogEntrySetField(r.Context(), "error", slog.AnyValue(err))
+		if apiErr, ok := uploadTooLargeError(err, maxUploadSize); ok {
+			return nil, apiErr
+		}
+		return nil, Error{
+			Code:    ErrCodeBadRequest,
+			Message: "Failed to parse form",
+		}
+	}
+
+	density := r.FormValue("density")
+	password := r.FormValue("password")
+
+	quality, err := parseQualityFormValue(r)
+	if err != nil {
+		return nil, err
+	}
+	width, err := parseSizeFormValue(r, "width")
+	if err != nil {
+		return nil, err
+	}
+	height, err := parseSizeFormValue(r, "height")
+	if err != nil {
+		return nil, err
+	}
+	maxPixels, err := parseSizeFormValue(r, "max_pixels")
+	if err != nil {
+		return nil, err
+	}
+
+	merge := parseBoolFormValue(r.FormValue("merge"))
+	pages := r.FormValue("pages")
+	format := r.FormValue("format")
+	renderer := r.FormValue("renderer")
+	includeText := parseBoolFormValue(r.FormValue("include_text"))
+	callbackURL := r.FormValue("callback_url")
+	documentURL := r.FormValue("url")
+
+	file, _, err := r.FormFile("data")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		httplog.LogEntrySetField(r.Context(), "error", slog.AnyValue(err))
+		return nil, Error{
+			Code:    ErrCodeBadRequest,
+			Message: "Failed to get uploaded file",
+		}
+	}
+
+	if (file == nil) == (documentURL == "") {
+		if file != nil {
+			file.Close()
+		}
+		return nil, errSourceRequired
+	}
+
+	return &ConvertRequest{
+		Password:    password,
+		Density:     density,
+		Quality:     quality,
+		Merge:       merge,
+		Pages:       pages,
+		Format:      format,
+		Width:       width,
+		Height:      height,
+		MaxPixels:   maxPixels,
+		Renderer:    renderer,
+		IncludeText: includeText,
+		CallbackURL: callbackURL,
+		URL:         documentURL,
+		File:        file,
+	}, nil
+}
+
+// PostConvert responds with JSON by default, or with a raw image, a ZIP
+// archive or a multipart/mixed body depending on the Accept header
+func PostConvert(impl ServiceImpl, options Options) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := r.ParseMultipartForm(32 << 20)
-		if err != nil {
-			respondWithError(w, r, Error{
-				Code:    ErrCodeBadRequest,
-				Message: "Failed to parse form",
-			}, http.StatusBadRequest, err)
+		ctx, span := StartSpan(r, "PostConvert")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		mode, imageFormat := negotiateResponseMode(r.Header.Get("Accept"))
+		if mode == responseModeUnsupported {
+			RespondWithError(w, r, Error{
+				Code:    ErrCodeNotAcceptable,
+				Message: "Accept must be application/json, image/* or a supported image type, application/zip or multipart/mixed",
+			}, http.StatusNotAcceptable, nil)
 			return
 		}
 
-		density := r.FormValue("density")
-		password := r.FormValue("password")
+		req, err := ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(Error)
+			RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
 
-		quality := 0
-		qualityStr := r.FormValue("quality")
-		if qualityStr != "" {
-			quality, err = strconv.Atoi(qualityStr)
+		if mode == responseModeJSON {
+			resp, err := impl.Convert(r.Context(), req)
 			if err != nil {
-				quality = 0
+				RespondWithConvertError(w, r, err)
+				return
 			}
-		}
 
-		merge := parseBoolFormValue(r.FormValue("merge"))
+			respondWithJSON(w, resp, http.StatusOK)
+			return
+		}
 
-		file, _, err := r.FormFile("data")
-		if err != nil {
-			respondWithError(w, r, Error{
-				Code:    ErrCodeBadRequest,
-				Message: "Failed to get uploaded file",
-			}, http.StatusBadRequest, err)
+		if conflictsWithImageFormat(req.Format, imageFormat) {
+			RespondWithError(w, r, Error{
+				Code:    ErrCodeNotAcceptable,
+				Message: fmt.Sprintf("Format %q does not match the image type of the Accept header", req.Format),
+			}, http.StatusNotAcceptable, nil)
 			return
 		}
-		defer file.Close()
 
-		req := ConvertRequest{
-			Password: password,
-			Density:  density,
-			Quality:  quality,
-			Merge:    merge,
-			File:     file,
+		if req.Format == "" {
+			req.Format = imageFormat
 		}
 
-		resp, err := impl.Convert(r.Context(), &req)
+		resp, err := impl.ConvertRaw(r.Context(), req)
 		if err != nil {
-			if apiErr, ok := err.(Error); ok {
-				respondWithError(w, r, apiErr, http.StatusBadRequest, err)
-			} else {
-				respondWithError(w, r, Error{
-					Code:    ErrCodeInternal,
-					Message: "Conversion failed: " + err.Error(),
-				}, http.StatusInternalServerError, err)
-			}
+			RespondWithConvertError(w, r, err)
 			return
 		}
 
-		respondWithJSON(w, resp, http.StatusOK)
+		switch mode {
+		case responseModeImage:
+			respondWithImage(w, r, resp)
+		case responseModeZip:
+			respondWithZip(w, r, resp)
+		case responseModeMultipart:
+			respondWithMultipart(w, r, resp)
+		}
 	}
 }
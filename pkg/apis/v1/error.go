@@ -1,5 +1,10 @@
 package v1
 
+import (
+	"context"
+	"net/http"
+)
+
 type ErrorCode int
 
 const (
@@ -7,13 +12,76 @@ const (
 	ErrCodeBadRequest
 	ErrCodeInternal
 	ErrCodePasswordRequired
+	ErrCodeInvalidPageRange
+	ErrCodeUnsupportedFormat
+	ErrCodeNotAcceptable
+	ErrCodeJobNotFound
+	ErrCodeJobNotFinished
+	ErrCodeQueueFull
+	ErrCodeInvalidCallbackURL
+	ErrCodeFetchForbidden
+	ErrCodeFetchFailed
+	ErrCodeServerBusy
+	ErrCodeResourceLimitExceeded
+	ErrCodeInvalidPassword
+	ErrCodeUnsupportedDocument
+	ErrCodeCorruptDocument
+	ErrCodeConversionTimeout
+	ErrCodeTooManyPages
+	ErrCodeEmptyDocument
+	ErrCodeUnsupportedRenderer
+	ErrCodeInvalidParameter
 )
 
+// Error is the body of error responses, a positive RetryAfter is sent as
+// the Retry-After header in seconds
 type Error struct {
-	Code    ErrorCode `json:"code"`
-	Message string    `json:"message"`
+	Code       ErrorCode `json:"code"`
+	Message    string    `json:"message"`
+	RetryAfter int       `json:"-"`
 }
 
 func (e Error) Error() string {
 	return e.Message
 }
+
+// StatusCode returns the HTTP status code used to respond with the error
+func (e Error) StatusCode() int {
+	switch e.Code {
+	case ErrCodeMaxFileSize:
+		return http.StatusRequestEntityTooLarge
+	case ErrCodeInternal:
+		return http.StatusInternalServerError
+	case ErrCodeNotAcceptable:
+		return http.StatusNotAcceptable
+	case ErrCodeJobNotFound:
+		return http.StatusNotFound
+	case ErrCodeJobNotFinished:
+		return http.StatusConflict
+	case ErrCodeQueueFull, ErrCodeServerBusy:
+		return http.StatusServiceUnavailable
+	case ErrCodeFetchFailed:
+		return http.StatusBadGateway
+	case ErrCodeUnsupportedDocument:
+		return http.StatusUnsupportedMediaType
+	case ErrCodeResourceLimitExceeded, ErrCodeCorruptDocument, ErrCodeConversionTimeout, ErrCodeTooManyPages:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+type errorCodeRecorderKey struct{}
+
+// WithErrorCodeRecorder returns a context whose error responses report their
+// code to record, e.g. to count the errors by code
+func WithErrorCodeRecorder(ctx context.Context, record func(ErrorCode)) context.Context {
+	return context.WithValue(ctx, errorCodeRecorderKey{}, record)
+}
+
+// RecordErrorCode reports the code of an error response to the recorder of the context
+func RecordErrorCode(ctx context.Context, code ErrorCode) {
+	if record, ok := ctx.Value(errorCodeRecorderKey{}).(func(ErrorCode)); ok {
+		record(code)
+	}
+}
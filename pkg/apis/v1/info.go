@@ -0,0 +1,59 @@
+package v1
+
+import "net/http"
+
+// DocumentMetadata is read from the document information dictionary
+type DocumentMetadata struct {
+	Title    string `json:"title,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Keywords string `json:"keywords,omitempty"`
+	Creator  string `json:"creator,omitempty"`
+	Producer string `json:"producer,omitempty"`
+}
+
+// PageInfo describes a page, Width and Height are in points with the page
+// rotation applied
+type PageInfo struct {
+	Page     int     `json:"page"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Rotation int     `json:"rotation"`
+	HasText  bool    `json:"has_text"`
+}
+
+// InfoResponse describes the structure of a document without rendering it
+type InfoResponse struct {
+	Id          string           `json:"id"`
+	PdfVersion  string           `json:"pdf_version"`
+	PageCount   int              `json:"page_count"`
+	IsEncrypted bool             `json:"encrypted"`
+	Metadata    DocumentMetadata `json:"metadata"`
+	Pages       []PageInfo       `json:"pages"`
+}
+
+// PostInfo accepts the document and password like PostConvert, the other
+// conversion parameters are ignored
+func PostInfo(impl ServiceImpl, options Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartSpan(r, "PostInfo")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		req, err := ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(Error)
+			RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
+
+		resp, err := impl.Info(r.Context(), req)
+		if err != nil {
+			RespondWithConvertError(w, r, err)
+			return
+		}
+
+		respondWithJSON(w, resp, http.StatusOK)
+	}
+}
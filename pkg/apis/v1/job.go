@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// WebhookDelivery describes an attempt to notify the callback URL of a job,
+// StatusCode is 0 when the receiver could not be reached
+type WebhookDelivery struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code"`
+	Error       string    `json:"error,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// JobResponse describes an asynchronous conversion, Error is set when it failed
+type JobResponse struct {
+	Id         string            `json:"id"`
+	Status     JobStatus         `json:"status"`
+	Error      *Error            `json:"error,omitempty"`
+	Deliveries []WebhookDelivery `json:"deliveries,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// JobCallback is the payload posted to the callback URL of a finished job,
+// Result is set when the job succeeded and Error when it failed
+type JobCallback struct {
+	JobResponse
+	Result *ConvertResponse `json:"result,omitempty"`
+}
+
+// PostJob accepts the same form parameters as PostConvert and responds as
+// soon as the conversion is queued
+func PostJob(impl ServiceImpl, options Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(Error)
+			RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
+
+		resp, err := impl.SubmitJob(r.Context(), req)
+		if err != nil {
+			RespondWithConvertError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Location", "/v1/jobs/"+resp.Id)
+		respondWithJSON(w, resp, http.StatusAccepted)
+	}
+}
+
+func GetJob(impl ServiceImpl) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := impl.GetJob(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			RespondWithConvertError(w, r, err)
+			return
+		}
+
+		respondWithJSON(w, resp, http.StatusOK)
+	}
+}
+
+// GetJobResult responds with the conversion result of a succeeded job or the
+// error of a failed job
+func GetJobResult(impl ServiceImpl) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := impl.GetJobResult(r.Context(), chi.URLParam(r, "id"))
+		if err != nil {
+			RespondWithConvertError(w, r, err)
+			return
+		}
+
+		respondWithJSON(w, resp, http.StatusOK)
+	}
+}
@@ -0,0 +1,288 @@
+package v1
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/httplog/v2"
+)
+
+const manifestFileName = "manifest.json"
+
+// RawImage is a converted image without base64 encoding, Page is zero when
+// the image merges multiple pages. Text is set when include_text is requested.
+type RawImage struct {
+	Page     int
+	Width    int
+	Height   int
+	MimeType string
+	Data     []byte
+	Text     *string
+}
+
+// Name returns the file name used in archives and attachments
+func (i *RawImage) Name() string {
+	extension := strings.TrimPrefix(i.MimeType, "image/")
+	if extension == "jpeg" {
+		extension = "jpg"
+	}
+
+	if i.Page == 0 {
+		return "merged." + extension
+	}
+
+	return fmt.Sprintf("page-%d.%s", i.Page, extension)
+}
+
+type ConvertRawResponse struct {
+	Id     string
+	Images []RawImage
+}
+
+type ManifestImage struct {
+	Name     string  `json:"name"`
+	Page     int     `json:"page"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	MimeType string  `json:"mime_type"`
+	Bytes    int     `json:"bytes"`
+	Text     *string `json:"text,omitempty"`
+}
+
+// Manifest describes the images inside ZIP and multipart responses
+type Manifest struct {
+	Id     string          `json:"id"`
+	Images []ManifestImage `json:"images"`
+}
+
+func newManifest(resp *ConvertRawResponse) *Manifest {
+	images := make([]ManifestImage, 0, len(resp.Images))
+	for i := range resp.Images {
+		image := &resp.Images[i]
+		images = append(images, ManifestImage{
+			Name:     image.Name(),
+			Page:     image.Page,
+			Width:    image.Width,
+			Height:   image.Height,
+			MimeType: image.MimeType,
+			Bytes:    len(image.Data),
+			Text:     image.Text,
+		})
+	}
+
+	return &Manifest{
+		Id:     resp.Id,
+		Images: images,
+	}
+}
+
+type responseMode int
+
+const (
+	responseModeUnsupported responseMode = iota
+	responseModeJSON
+	responseModeImage
+	responseModeZip
+	responseModeMultipart
+)
+
+// imageFormats maps the image media types which can be negotiated to their
+// output format
+var imageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+	"image/avif": "avif",
+	"image/tiff": "tiff",
+}
+
+// imageFormatAliases are the alternative names of the format parameter
+var imageFormatAliases = map[string]string{
+	"jpg": "jpeg",
+	"tif": "tiff",
+}
+
+// conflictsWithImageFormat reports whether the format parameter selects
+// another format than the negotiated image type
+func conflictsWithImageFormat(format, imageFormat string) bool {
+	if format == "" || imageFormat == "" {
+		return false
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	if alias, ok := imageFormatAliases[format]; ok {
+		format = alias
+	}
+
+	return format != imageFormat
+}
+
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateResponseMode picks the preferred response mode from the Accept
+// header and the image format when a concrete image type is requested, image
+// types which cannot be produced are skipped
+func negotiateResponseMode(accept string) (responseMode, string) {
+	if strings.TrimSpace(accept) == "" {
+		return responseModeJSON, ""
+	}
+
+	var ranges []mediaRange
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, candidate := range ranges {
+		switch {
+		case candidate.mediaType == "*/*",
+			candidate.mediaType == "application/*",
+			candidate.mediaType == "application/json":
+			return responseModeJSON, ""
+		case candidate.mediaType == "image/*":
+			return responseModeImage, ""
+		case imageFormats[candidate.mediaType] != "":
+			return responseModeImage, imageFormats[candidate.mediaType]
+		case candidate.mediaType == "application/zip":
+			return responseModeZip, ""
+		case candidate.mediaType == "multipart/*",
+			candidate.mediaType == "multipart/mixed":
+			return responseModeMultipart, ""
+		}
+	}
+
+	return responseModeUnsupported, ""
+}
+
+func respondWithImage(w http.ResponseWriter, r *http.Request, resp *ConvertRawResponse) {
+	if len(resp.Images) != 1 {
+		RespondWithError(w, r, Error{
+			Code:    ErrCodeNotAcceptable,
+			Message: fmt.Sprintf("Raw image response requires a single image, got %d, select one page or enable merge", len(resp.Images)),
+		}, http.StatusNotAcceptable, nil)
+		return
+	}
+
+	image := &resp.Images[0]
+	w.Header().Set("Content-Type", image.MimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(image.Data)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": image.Name()}))
+	w.Header().Set("X-File-Id", resp.Id)
+	w.Header().Set("X-Page", strconv.Itoa(image.Page))
+	w.Header().Set("X-Image-Width", strconv.Itoa(image.Width))
+	w.Header().Set("X-Image-Height", strconv.Itoa(image.Height))
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(image.Data); err != nil {
+		httplog.LogEntrySetField(r.Context(), "write_error", slog.AnyValue(err))
+	}
+}
+
+func respondWithZip(w http.ResponseWriter, r *http.Request, resp *ConvertRawResponse) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": resp.Id + ".zip"}))
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeZip(w, resp); err != nil {
+		httplog.LogEntrySetField(r.Context(), "write_error", slog.AnyValue(err))
+	}
+}
+
+func writeZip(w io.Writer, resp *ConvertRawResponse) error {
+	archive := zip.NewWriter(w)
+
+	manifest, err := archive.Create(manifestFileName)
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(manifest).Encode(newManifest(resp)); err != nil {
+		return err
+	}
+
+	for i := range resp.Images {
+		image := &resp.Images[i]
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:   image.Name(),
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := entry.Write(image.Data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func respondWithMultipart(w http.ResponseWriter, r *http.Request, resp *ConvertRawResponse) {
+	writer := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeMultipart(writer, resp); err != nil {
+		httplog.LogEntrySetField(r.Context(), "write_error", slog.AnyValue(err))
+	}
+}
+
+func writeMultipart(writer *multipart.Writer, resp *ConvertRawResponse) error {
+	manifest, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":        {"application/json"},
+		"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": manifestFileName})},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := json.NewEncoder(manifest).Encode(newManifest(resp)); err != nil {
+		return err
+	}
+
+	for i := range resp.Images {
+		image := &resp.Images[i]
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":        {image.MimeType},
+			"Content-Disposition": {mime.FormatMediaType("attachment", map[string]string{"filename": image.Name()})},
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := part.Write(image.Data); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,41 @@
+package v1
+
+import "net/http"
+
+// PageText is the text layer of a page, empty when the page has none
+type PageText struct {
+	Page int    `json:"page"`
+	Text string `json:"text"`
+}
+
+// TextResponse contains the text of the selected pages in page order
+type TextResponse struct {
+	Id    string     `json:"id"`
+	Pages []PageText `json:"pages"`
+}
+
+// PostText accepts the document, password and pages like PostConvert and
+// responds with the text layer instead of images
+func PostText(impl ServiceImpl, options Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartSpan(r, "PostText")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		req, err := ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(Error)
+			RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
+
+		resp, err := impl.Text(r.Context(), req)
+		if err != nil {
+			RespondWithConvertError(w, r, err)
+			return
+		}
+
+		respondWithJSON(w, resp, http.StatusOK)
+	}
+}
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/elct9620/pdf64/pkg/apis")
+
+// StartSpan starts a server span for the handler, the caller must end it
+func StartSpan(r *http.Request, name string) (context.Context, trace.Span) {
+	return tracer.Start(r.Context(), name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+}
@@ -0,0 +1,29 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	"github.com/go-chi/chi/v5"
+)
+
+type ServiceImpl interface {
+	Convert(ctx context.Context, req *v1.ConvertRequest) (*ConvertResponse, error)
+	ConvertStream(ctx context.Context, req *v1.ConvertRequest, emit func(event *ImageEvent) error) error
+}
+
+func Register(r chi.Router, impl ServiceImpl, options v1.Options) {
+	r.Post("/v2/convert", PostConvert(impl, options))
+	r.Post("/v2/convert/stream", PostConvertStream(impl, options))
+}
+
+func respondWithJSON(w http.ResponseWriter, data any, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.Error("Failed to encode JSON response", "error", err)
+	}
+}
@@ -0,0 +1,49 @@
+package v2
+
+import (
+	"net/http"
+
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+)
+
+// Image is a converted page, Page is zero when the image merges multiple pages
+type Image struct {
+	Page     int     `json:"page"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+	MimeType string  `json:"mime_type"`
+	Bytes    int     `json:"bytes"`
+	Data     string  `json:"data"`
+	Renderer string  `json:"renderer,omitempty"`
+	Text     *string `json:"text,omitempty"`
+}
+
+type ConvertResponse struct {
+	Id   string  `json:"id"`
+	Data []Image `json:"data"`
+}
+
+// PostConvert accepts the same form parameters as the v1 endpoint
+func PostConvert(impl ServiceImpl, options v1.Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := v1.StartSpan(r, "PostConvert")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		req, err := v1.ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(v1.Error)
+			v1.RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
+
+		resp, err := impl.Convert(r.Context(), req)
+		if err != nil {
+			v1.RespondWithConvertError(w, r, err)
+			return
+		}
+
+		respondWithJSON(w, resp, http.StatusOK)
+	}
+}
@@ -0,0 +1,169 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+
+	v1 "github.com/elct9620/pdf64/pkg/apis/v1"
+	"github.com/go-chi/httplog/v2"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	ContentTypeNDJSON      = "application/x-ndjson"
+	ContentTypeEventStream = "text/event-stream"
+)
+
+// ImageEvent is a single converted image emitted by the streaming endpoint
+type ImageEvent struct {
+	Id string `json:"id"`
+	Image
+}
+
+// StreamError is emitted when the conversion fails after streaming started
+type StreamError struct {
+	Error v1.Error `json:"error"`
+}
+
+// streamWriter writes events as NDJSON lines or Server-Sent Events and
+// flushes each of them to the client immediately
+type streamWriter struct {
+	w             http.ResponseWriter
+	controller    *http.ResponseController
+	isEventStream bool
+	isStarted     bool
+	count         int
+}
+
+func newStreamWriter(w http.ResponseWriter, r *http.Request) *streamWriter {
+	return &streamWriter{
+		w:             w,
+		controller:    http.NewResponseController(w),
+		isEventStream: acceptsEventStream(r.Header.Get("Accept")),
+	}
+}
+
+func (s *streamWriter) WriteImage(event *ImageEvent) error {
+	s.count++
+	return s.write("page", event)
+}
+
+func (s *streamWriter) WriteError(err v1.Error) error {
+	return s.write("error", StreamError{Error: err})
+}
+
+// Close marks the end of an event stream so that clients stop reconnecting
+func (s *streamWriter) Close(id string) error {
+	if !s.isEventStream {
+		return nil
+	}
+
+	return s.write("end", map[string]any{"id": id, "count": s.count})
+}
+
+func (s *streamWriter) write(event string, data any) error {
+	if !s.isStarted {
+		s.start()
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if s.isEventStream {
+		_, err = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload)
+	} else {
+		_, err = s.w.Write(append(payload, '\n'))
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *streamWriter) start() {
+	s.isStarted = true
+
+	contentType := ContentTypeNDJSON
+	if s.isEventStream {
+		contentType = ContentTypeEventStream
+	}
+
+	s.w.Header().Set("Content-Type", contentType)
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+func acceptsEventStream(accept string) bool {
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err == nil && mediaType == ContentTypeEventStream {
+			return true
+		}
+	}
+	return false
+}
+
+// PostConvertStream accepts the same form parameters as the v1 endpoint and
+// streams each page as NDJSON, or as Server-Sent Events when requested by the
+// Accept header
+func PostConvertStream(impl ServiceImpl, options v1.Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := v1.StartSpan(r, "PostConvertStream")
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		req, err := v1.ParseConvertRequest(w, r, options)
+		if err != nil {
+			apiErr := err.(v1.Error)
+			v1.RespondWithError(w, r, apiErr, apiErr.StatusCode(), nil)
+			return
+		}
+		defer req.Close()
+
+		var id string
+		stream := newStreamWriter(w, r)
+		err = impl.ConvertStream(r.Context(), req, func(event *ImageEvent) error {
+			id = event.Id
+			return stream.WriteImage(event)
+		})
+		if err != nil {
+			if !stream.isStarted {
+				v1.RespondWithConvertError(w, r, err)
+				return
+			}
+
+			httplog.LogEntrySetField(r.Context(), "error", slog.AnyValue(err))
+			apiErr, ok := err.(v1.Error)
+			if !ok {
+				apiErr = v1.Error{
+					Code:    v1.ErrCodeInternal,
+					Message: "Conversion failed",
+				}
+			}
+			v1.RecordErrorCode(r.Context(), apiErr.Code)
+			span.SetAttributes(attribute.Int("pdf64.error_code", int(apiErr.Code)))
+
+			if writeErr := stream.WriteError(apiErr); writeErr != nil {
+				httplog.LogEntrySetField(r.Context(), "stream_error", slog.AnyValue(writeErr))
+			}
+			return
+		}
+
+		if err := stream.Close(id); err != nil {
+			httplog.LogEntrySetField(r.Context(), "stream_error", slog.AnyValue(err))
+		}
+	}
+}